@@ -0,0 +1,132 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	// Embedded zone data lets TIME_ZONE load on hosts without a zoneinfo
+	// database.
+	_ "time/tzdata"
+)
+
+const defaultDSN = "username:password@tcp(localhost:3306)/your_db_name?charset=utf8mb4&parseTime=True&loc=Local"
+
+// Config is read from the environment once at startup.
+type Config struct {
+	DatabaseDSN string
+
+	// JWTAlgorithm is HS256, signing access tokens with JWTSecret, or RS256,
+	// signing with the PEM key in JWTPrivateKeyFile under JWTKeyID and
+	// verifying against the key set in JWTJWKSFile.
+	JWTAlgorithm      string
+	JWTSecret         string
+	JWTRefreshSecret  string
+	JWTPrivateKeyFile string
+	JWTKeyID          string
+	JWTJWKSFile       string
+	JWTIssuer         string
+	JWTAudience       string
+	AccessTokenTTL    time.Duration
+	RefreshTokenTTL   time.Duration
+
+	// AdminUsername and AdminPassword create the first admin account when
+	// no user with that name exists yet.
+	AdminUsername string
+	AdminPassword string
+
+	RollNumberFormat  string
+	RollNumberPattern string
+
+	// PDFCacheSize is how many rendered PDFs are kept for repeat downloads;
+	// 0 turns the cache off.
+	PDFCacheSize int
+
+	// TimeZone is the zone printed documents show exam times in.
+	TimeZone *time.Location
+}
+
+func Load() (Config, error) {
+	cfg := Config{
+		DatabaseDSN:       getEnv("DB_DSN", defaultDSN),
+		JWTAlgorithm:      strings.ToUpper(getEnv("JWT_ALGORITHM", "HS256")),
+		JWTSecret:         os.Getenv("JWT_SECRET"),
+		JWTRefreshSecret:  os.Getenv("JWT_REFRESH_SECRET"),
+		JWTPrivateKeyFile: os.Getenv("JWT_PRIVATE_KEY_FILE"),
+		JWTKeyID:          os.Getenv("JWT_KEY_ID"),
+		JWTJWKSFile:       os.Getenv("JWT_JWKS_FILE"),
+		JWTIssuer:         getEnv("JWT_ISSUER", "ums"),
+		JWTAudience:       getEnv("JWT_AUDIENCE", "ums-api"),
+		AdminUsername:     os.Getenv("ADMIN_USERNAME"),
+		AdminPassword:     os.Getenv("ADMIN_PASSWORD"),
+		RollNumberFormat:  os.Getenv("ROLL_NUMBER_FORMAT"),
+		RollNumberPattern: os.Getenv("ROLL_NUMBER_PATTERN"),
+	}
+
+	switch cfg.JWTAlgorithm {
+	case "HS256":
+		if len(cfg.JWTSecret) < 32 {
+			return Config{}, fmt.Errorf("JWT_SECRET must be set to at least 32 characters")
+		}
+	case "RS256":
+		if cfg.JWTPrivateKeyFile == "" || cfg.JWTKeyID == "" || cfg.JWTJWKSFile == "" {
+			return Config{}, fmt.Errorf("RS256 needs JWT_PRIVATE_KEY_FILE, JWT_KEY_ID and JWT_JWKS_FILE")
+		}
+	default:
+		return Config{}, fmt.Errorf("JWT_ALGORITHM must be HS256 or RS256")
+	}
+	if cfg.JWTRefreshSecret == "" {
+		cfg.JWTRefreshSecret = cfg.JWTSecret
+	}
+	if len(cfg.JWTRefreshSecret) < 32 {
+		return Config{}, fmt.Errorf("JWT_REFRESH_SECRET must be set to at least 32 characters")
+	}
+
+	var err error
+	if cfg.AccessTokenTTL, err = getDuration("ACCESS_TOKEN_TTL", 15*time.Minute); err != nil {
+		return Config{}, err
+	}
+	if cfg.RefreshTokenTTL, err = getDuration("REFRESH_TOKEN_TTL", 7*24*time.Hour); err != nil {
+		return Config{}, err
+	}
+	if cfg.PDFCacheSize, err = getInt("PDF_CACHE_SIZE", 32); err != nil {
+		return Config{}, err
+	}
+	if cfg.TimeZone, err = time.LoadLocation(getEnv("TIME_ZONE", "UTC")); err != nil {
+		return Config{}, fmt.Errorf("TIME_ZONE must be a time zone name such as Asia/Kolkata")
+	}
+	return cfg, nil
+}
+
+func getEnv(name, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return fallback
+}
+
+func getDuration(name string, fallback time.Duration) (time.Duration, error) {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback, nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		return 0, fmt.Errorf("%s must be a positive duration such as 15m", name)
+	}
+	return duration, nil
+}
+
+func getInt(name string, fallback int) (int, error) {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback, nil
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil || number < 0 {
+		return 0, fmt.Errorf("%s must be a whole number of zero or more", name)
+	}
+	return number, nil
+}
@@ -0,0 +1,311 @@
+package db
+
+import (
+	"DevMaan707/UMS/models"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrNotFound = errors.New("record not found")
+
+// AssignmentFilter selects a seating plan either by exam session or, for
+// plans made without one, by the exact time of exam; a time of exam never
+// selects a session's plans. With neither set it selects every plan still
+// running after EndsAfter. InvigilatorID further narrows it to the plans that
+// invigilator is on duty in.
+type AssignmentFilter struct {
+	SessionID     uint
+	TOE           time.Time
+	EndsAfter     time.Time
+	InvigilatorID uint
+}
+
+func (f AssignmentFilter) apply(query *gorm.DB) *gorm.DB {
+	if f.InvigilatorID != 0 {
+		onDuty := query.Session(&gorm.Session{NewDB: true}).Model(&models.Invigilation{}).
+			Select("exam_assignment_id").Where("user_id = ?", f.InvigilatorID)
+		query = query.Where("id IN (?)", onDuty)
+	}
+	if f.SessionID != 0 {
+		return query.Where("exam_session_id = ?", f.SessionID)
+	}
+	if f.TOE.IsZero() {
+		return query.Where("end_time > ?", f.EndsAfter.UTC())
+	}
+	return query.Where("exam_session_id = ? AND toe = ?", 0, f.TOE.UTC())
+}
+
+// Matches reports whether the filter selects a room plan. It cannot see duty
+// rosters, so it ignores InvigilatorID.
+func (f AssignmentFilter) Matches(assignment models.ExamAssignment) bool {
+	if f.SessionID != 0 {
+		return assignment.ExamSessionID == f.SessionID
+	}
+	if f.TOE.IsZero() {
+		return assignment.EndTime.After(f.EndsAfter)
+	}
+	return assignment.ExamSessionID == 0 && assignment.TOE.Equal(f.TOE)
+}
+
+// SaveOptions controls how SaveExamAssignments commits a plan.
+type SaveOptions struct {
+	// Replace deletes the plans it selects in the same transaction, so a
+	// session's plan is swapped without a moment where it has none. It must
+	// name a session or a time of exam.
+	Replace *AssignmentFilter
+	// Check is given the stored plans overlapping the new ones, locked and
+	// without any replaced plans. An error from it aborts the save.
+	Check func(existing []models.ExamAssignment) error
+}
+
+// AssignmentStore persists exam seating plans, one ExamAssignment per room
+// with its seats stored as individual SeatAssignment rows.
+type AssignmentStore interface {
+	SaveExamAssignments(assignments []models.ExamAssignment, options SaveOptions) error
+	DeleteExamAssignment(id uint) error
+	DeleteExamAssignments(filter AssignmentFilter) (int, error)
+	FetchExamAssignments(filter AssignmentFilter) ([]models.ExamAssignment, error)
+	FindStudentAssignments(studentID string, filter AssignmentFilter) ([]models.ExamAssignment, error)
+	FetchExamAssignmentsInWindow(from, to time.Time) ([]models.ExamAssignment, error)
+	SetInvigilators(examAssignmentID uint, userIDs []uint) error
+	FetchInvigilatorAssignments(userID uint, filter AssignmentFilter) ([]models.ExamAssignment, error)
+}
+
+type gormAssignmentStore struct {
+	db *gorm.DB
+	// saving serialises plan commits so two plans cannot both pass the
+	// conflict check before either is stored.
+	saving sync.Mutex
+}
+
+func NewAssignmentStore(db *gorm.DB) AssignmentStore {
+	return &gormAssignmentStore{db: db}
+}
+
+// SaveExamAssignments stores a plan, first deleting the plans it replaces
+// and re-checking it against the stored plans it overlaps.
+func (s *gormAssignmentStore) SaveExamAssignments(assignments []models.ExamAssignment, options SaveOptions) error {
+	if options.Replace != nil && options.Replace.SessionID == 0 && options.Replace.TOE.IsZero() {
+		return errors.New("error saving exam assignments: replace needs a session or time of exam")
+	}
+	if len(assignments) == 0 && options.Replace == nil {
+		return nil
+	}
+
+	s.saving.Lock()
+	defer s.saving.Unlock()
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if options.Replace != nil {
+			var ids []uint
+			if err := options.Replace.apply(tx.Model(&models.ExamAssignment{})).Pluck("id", &ids).Error; err != nil {
+				return err
+			}
+			if err := deleteExamAssignments(tx, ids); err != nil {
+				return err
+			}
+		}
+		if len(assignments) == 0 {
+			return nil
+		}
+
+		if options.Check != nil {
+			from, to := assignments[0].TOE, assignments[0].EndTime
+			for _, assignment := range assignments[1:] {
+				if assignment.TOE.Before(from) {
+					from = assignment.TOE
+				}
+				if assignment.EndTime.After(to) {
+					to = assignment.EndTime
+				}
+			}
+			// Locking the overlapping plans keeps another server from
+			// committing a clash between this check and the insert.
+			var ids []uint
+			locked := windowQuery(tx.Model(&models.ExamAssignment{}), from, to).Clauses(clause.Locking{Strength: "UPDATE"})
+			if err := locked.Pluck("id", &ids).Error; err != nil {
+				return err
+			}
+			existing, err := fetchInWindow(tx, from, to)
+			if err != nil {
+				return err
+			}
+			if err := options.Check(existing); err != nil {
+				return err
+			}
+		}
+		return tx.Create(&assignments).Error
+	})
+	if err != nil {
+		return fmt.Errorf("error saving exam assignments: %w", err)
+	}
+	return nil
+}
+
+// DeleteExamAssignment removes one room plan with its seats and
+// invigilators.
+func (s *gormAssignmentStore) DeleteExamAssignment(id uint) error {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&models.ExamAssignment{}).Where("id = ?", id).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return ErrNotFound
+		}
+		return deleteExamAssignments(tx, []uint{id})
+	})
+	if errors.Is(err, ErrNotFound) {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("error deleting exam assignment: %w", err)
+	}
+	return nil
+}
+
+// DeleteExamAssignments removes every room plan of a session or time of exam
+// and returns how many there were.
+func (s *gormAssignmentStore) DeleteExamAssignments(filter AssignmentFilter) (int, error) {
+	if filter.SessionID == 0 && filter.TOE.IsZero() {
+		return 0, errors.New("error deleting exam assignments: no session or time of exam")
+	}
+	var ids []uint
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := filter.apply(tx.Model(&models.ExamAssignment{})).Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		return deleteExamAssignments(tx, ids)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("error deleting exam assignments: %w", err)
+	}
+	return len(ids), nil
+}
+
+func (s *gormAssignmentStore) FetchExamAssignments(filter AssignmentFilter) ([]models.ExamAssignment, error) {
+	var assignments []models.ExamAssignment
+	query := s.db.Preload("Seats", func(db *gorm.DB) *gorm.DB { return db.Order("id") })
+	err := filter.apply(query).Order("id").Find(&assignments).Error
+	if err != nil {
+		return nil, fmt.Errorf("error fetching exam assignments: %w", err)
+	}
+	return assignments, nil
+}
+
+// FindStudentAssignments returns the room plans that seat a student, in exam
+// order, each with only that student's seat loaded.
+func (s *gormAssignmentStore) FindStudentAssignments(studentID string, filter AssignmentFilter) ([]models.ExamAssignment, error) {
+	seated := s.db.Model(&models.SeatAssignment{}).Select("exam_assignment_id").Where("student_id = ?", studentID)
+	query := s.db.Preload("Seats", "student_id = ?", studentID).Where("id IN (?)", seated)
+
+	var assignments []models.ExamAssignment
+	if err := filter.apply(query).Order("toe").Order("id").Find(&assignments).Error; err != nil {
+		return nil, fmt.Errorf("error fetching student assignments: %w", err)
+	}
+	if len(assignments) == 0 {
+		return nil, ErrNotFound
+	}
+	return assignments, nil
+}
+
+// FetchExamAssignmentsInWindow returns every room plan whose exam overlaps
+// [from, to). A zero bound leaves that side of the window open.
+func (s *gormAssignmentStore) FetchExamAssignmentsInWindow(from, to time.Time) ([]models.ExamAssignment, error) {
+	assignments, err := fetchInWindow(s.db, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching exam assignments: %w", err)
+	}
+	return assignments, nil
+}
+
+func windowQuery(query *gorm.DB, from, to time.Time) *gorm.DB {
+	if !to.IsZero() {
+		query = query.Where("toe < ?", to.UTC())
+	}
+	if !from.IsZero() {
+		query = query.Where("end_time > ?", from.UTC())
+	}
+	return query
+}
+
+func fetchInWindow(db *gorm.DB, from, to time.Time) ([]models.ExamAssignment, error) {
+	query := db.Preload("Seats", func(db *gorm.DB) *gorm.DB { return db.Order("id") })
+	var assignments []models.ExamAssignment
+	if err := windowQuery(query, from, to).Order("toe").Order("id").Find(&assignments).Error; err != nil {
+		return nil, err
+	}
+	return assignments, nil
+}
+
+// SetInvigilators replaces the invigilators on duty in one room plan.
+func (s *gormAssignmentStore) SetInvigilators(examAssignmentID uint, userIDs []uint) error {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&models.ExamAssignment{}).Where("id = ?", examAssignmentID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return ErrNotFound
+		}
+
+		if err := tx.Where("exam_assignment_id = ?", examAssignmentID).Delete(&models.Invigilation{}).Error; err != nil {
+			return err
+		}
+		if len(userIDs) == 0 {
+			return nil
+		}
+		invigilations := make([]models.Invigilation, 0, len(userIDs))
+		for _, userID := range userIDs {
+			invigilations = append(invigilations, models.Invigilation{ExamAssignmentID: examAssignmentID, UserID: userID})
+		}
+		return tx.Create(&invigilations).Error
+	})
+	if errors.Is(err, ErrNotFound) {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("error setting invigilators: %w", err)
+	}
+	return nil
+}
+
+func (s *gormAssignmentStore) FetchInvigilatorAssignments(userID uint, filter AssignmentFilter) ([]models.ExamAssignment, error) {
+	filter.InvigilatorID = userID
+	query := s.db.Preload("Seats", func(db *gorm.DB) *gorm.DB { return db.Order("id") })
+
+	var assignments []models.ExamAssignment
+	if err := filter.apply(query).Order("toe").Order("id").Find(&assignments).Error; err != nil {
+		return nil, fmt.Errorf("error fetching invigilator assignments: %w", err)
+	}
+	return assignments, nil
+}
+
+// deleteExamAssignments removes room plans along with their seats and
+// invigilators.
+func deleteExamAssignments(tx *gorm.DB, ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	if err := tx.Where("exam_assignment_id IN ?", ids).Delete(&models.Invigilation{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("exam_assignment_id IN ?", ids).Delete(&models.SeatAssignment{}).Error; err != nil {
+		return err
+	}
+	return tx.Delete(&models.ExamAssignment{}, ids).Error
+}
+
+// deleteSessionPlans removes every room plan made for the given exam
+// sessions.
+func deleteSessionPlans(tx *gorm.DB, sessionIDs []uint) error {
+	var ids []uint
+	if err := tx.Model(&models.ExamAssignment{}).Where("exam_session_id IN ?", sessionIDs).Pluck("id", &ids).Error; err != nil {
+		return err
+	}
+	return deleteExamAssignments(tx, ids)
+}
@@ -0,0 +1,180 @@
+package db
+
+import (
+	"DevMaan707/UMS/models"
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var ErrConflict = errors.New("record already exists")
+
+type ClassFilter struct {
+	Branches []string
+	Years    []int
+	Sections []string
+}
+
+// ClassStore keeps the class rosters used for seating. Every student ID in
+// Class.StudentIDs has a matching Student row carrying the student's details.
+type ClassStore interface {
+	CreateClasses(classes []models.Class) error
+	ListClasses(filter ClassFilter) ([]models.Class, error)
+	GetClass(id uint) (models.Class, error)
+	AddStudents(classID uint, students []models.Student) (models.Class, error)
+	UpdateEligibility(class *models.Class) error
+	FindStudents(studentIDs []string) ([]models.Student, error)
+}
+
+type gormClassStore struct {
+	db *gorm.DB
+}
+
+func NewClassStore(db *gorm.DB) ClassStore {
+	return &gormClassStore{db: db}
+}
+
+func (s *gormClassStore) CreateClasses(classes []models.Class) error {
+	if len(classes) == 0 {
+		return nil
+	}
+
+	var studentIDs []string
+	for _, class := range classes {
+		studentIDs = append(studentIDs, class.StudentIDs...)
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := ensureNewStudents(tx, studentIDs); err != nil {
+			return err
+		}
+		return tx.Create(&classes).Error
+	})
+	if errors.Is(err, ErrConflict) {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("error creating classes: %w", err)
+	}
+	return nil
+}
+
+func (s *gormClassStore) ListClasses(filter ClassFilter) ([]models.Class, error) {
+	query := s.db.Model(&models.Class{})
+	if len(filter.Branches) > 0 {
+		query = query.Where("branch IN ?", filter.Branches)
+	}
+	if len(filter.Years) > 0 {
+		query = query.Where("year IN ?", filter.Years)
+	}
+	if len(filter.Sections) > 0 {
+		query = query.Where("section IN ?", filter.Sections)
+	}
+
+	var classes []models.Class
+	if err := query.Order("branch").Order("year").Order("section").Find(&classes).Error; err != nil {
+		return nil, fmt.Errorf("error listing classes: %w", err)
+	}
+	return classes, nil
+}
+
+func (s *gormClassStore) GetClass(id uint) (models.Class, error) {
+	var class models.Class
+	err := s.db.Preload("Students", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).First(&class, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.Class{}, ErrNotFound
+	}
+	if err != nil {
+		return models.Class{}, fmt.Errorf("error fetching class: %w", err)
+	}
+	return class, nil
+}
+
+func (s *gormClassStore) AddStudents(classID uint, students []models.Student) (models.Class, error) {
+	var class models.Class
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&class, classID).Error; err != nil {
+			return err
+		}
+
+		studentIDs := make([]string, 0, len(students))
+		for i := range students {
+			students[i].ClassID = class.ID
+			students[i].Branch = class.Branch
+			students[i].Year = class.Year
+			students[i].Section = class.Section
+			studentIDs = append(studentIDs, students[i].StudentID)
+		}
+		if err := ensureNewStudents(tx, studentIDs); err != nil {
+			return err
+		}
+		if len(students) > 0 {
+			if err := tx.Create(&students).Error; err != nil {
+				return err
+			}
+		}
+
+		class.StudentIDs = append(class.StudentIDs, studentIDs...)
+		return tx.Model(&class).Select("StudentIDs").Updates(&class).Error
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.Class{}, ErrNotFound
+	}
+	if errors.Is(err, ErrConflict) {
+		return models.Class{}, err
+	}
+	if err != nil {
+		return models.Class{}, fmt.Errorf("error adding students: %w", err)
+	}
+	return s.GetClass(classID)
+}
+
+func (s *gormClassStore) UpdateEligibility(class *models.Class) error {
+	err := s.db.Model(class).Select("DetainedList", "DebarredList").Updates(class).Error
+	if err != nil {
+		return fmt.Errorf("error updating class eligibility: %w", err)
+	}
+	return nil
+}
+
+// FindStudents returns the Student rows for the given IDs; unknown IDs are
+// skipped.
+func (s *gormClassStore) FindStudents(studentIDs []string) ([]models.Student, error) {
+	if len(studentIDs) == 0 {
+		return []models.Student{}, nil
+	}
+	var students []models.Student
+	if err := s.db.Where("student_id IN ?", studentIDs).Order("student_id").Find(&students).Error; err != nil {
+		return nil, fmt.Errorf("error fetching students: %w", err)
+	}
+	return students, nil
+}
+
+func ensureNewStudents(tx *gorm.DB, studentIDs []string) error {
+	if len(studentIDs) == 0 {
+		return nil
+	}
+
+	seen := map[string]bool{}
+	var duplicates []string
+	for _, studentID := range studentIDs {
+		if seen[studentID] {
+			duplicates = append(duplicates, studentID)
+		}
+		seen[studentID] = true
+	}
+	if len(duplicates) > 0 {
+		return fmt.Errorf("%w: duplicate students %s", ErrConflict, strings.Join(duplicates, ", "))
+	}
+
+	var existing []string
+	if err := tx.Model(&models.Student{}).Where("student_id IN ?", studentIDs).Pluck("student_id", &existing).Error; err != nil {
+		return err
+	}
+	if len(existing) > 0 {
+		return fmt.Errorf("%w: students %s", ErrConflict, strings.Join(existing, ", "))
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package db
 
 import (
+	"DevMaan707/UMS/models"
 	"fmt"
 	"log"
 
@@ -9,10 +10,12 @@ import (
 	"gorm.io/gorm/logger"
 )
 
-func ConnectMySQL() *gorm.DB {
-	dsn := "username:password@tcp(localhost:3306)/your_db_name?charset=utf8mb4&parseTime=True&loc=Local"
+func ConnectMySQL(dsn string) *gorm.DB {
+	// TranslateError turns duplicate keys into gorm.ErrDuplicatedKey, which
+	// the stores report as ErrConflict.
 	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Info),
+		Logger:         logger.Default.LogMode(logger.Info),
+		TranslateError: true,
 	})
 	if err != nil {
 		log.Fatalf("Error connecting to database: %v", err)
@@ -21,3 +24,24 @@ func ConnectMySQL() *gorm.DB {
 
 	return db
 }
+
+func Migrate(db *gorm.DB) error {
+	err := db.AutoMigrate(
+		&models.Class{},
+		&models.Student{},
+		&models.Room{},
+		&models.Exam{},
+		&models.ExamSession{},
+		&models.ExamPaper{},
+		&models.ExamAssignment{},
+		&models.SeatAssignment{},
+		&models.User{},
+		&models.Invigilation{},
+		&models.TokenRevocation{},
+		&models.UserRevocation{},
+	)
+	if err != nil {
+		return fmt.Errorf("error migrating tables: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package db
+
+import (
+	"DevMaan707/UMS/models"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// ExamStore manages exams along with their sessions and the papers written
+// in each session.
+type ExamStore interface {
+	CreateExam(exam *models.Exam) error
+	ListExams() ([]models.Exam, error)
+	GetExam(id uint) (models.Exam, error)
+	UpdateExam(exam *models.Exam) error
+	DeleteExam(id uint) error
+	AddSession(session *models.ExamSession) error
+	GetSession(id uint) (models.ExamSession, error)
+	DeleteSession(examID, sessionID uint) error
+}
+
+type gormExamStore struct {
+	db *gorm.DB
+}
+
+func NewExamStore(db *gorm.DB) ExamStore {
+	return &gormExamStore{db: db}
+}
+
+func preloadSessions(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("Sessions", func(db *gorm.DB) *gorm.DB { return db.Order("start_time") }).
+		Preload("Sessions.Papers", func(db *gorm.DB) *gorm.DB { return db.Order("id") })
+}
+
+func (s *gormExamStore) CreateExam(exam *models.Exam) error {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		return tx.Create(exam).Error
+	})
+	if err != nil {
+		return fmt.Errorf("error creating exam: %w", err)
+	}
+	return nil
+}
+
+func (s *gormExamStore) ListExams() ([]models.Exam, error) {
+	var exams []models.Exam
+	if err := preloadSessions(s.db).Order("id").Find(&exams).Error; err != nil {
+		return nil, fmt.Errorf("error listing exams: %w", err)
+	}
+	return exams, nil
+}
+
+func (s *gormExamStore) GetExam(id uint) (models.Exam, error) {
+	var exam models.Exam
+	err := preloadSessions(s.db).First(&exam, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.Exam{}, ErrNotFound
+	}
+	if err != nil {
+		return models.Exam{}, fmt.Errorf("error fetching exam: %w", err)
+	}
+	return exam, nil
+}
+
+func (s *gormExamStore) UpdateExam(exam *models.Exam) error {
+	err := s.db.Model(exam).Select("ExamName", "ExamType").Updates(exam).Error
+	if err != nil {
+		return fmt.Errorf("error updating exam: %w", err)
+	}
+	return nil
+}
+
+// DeleteExam removes an exam with its sessions, their papers and every
+// seating plan made for them.
+func (s *gormExamStore) DeleteExam(id uint) error {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&models.Exam{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+
+		var sessionIDs []uint
+		if err := tx.Model(&models.ExamSession{}).Where("exam_id = ?", id).Pluck("id", &sessionIDs).Error; err != nil {
+			return err
+		}
+		if len(sessionIDs) == 0 {
+			return nil
+		}
+		if err := tx.Where("exam_session_id IN ?", sessionIDs).Delete(&models.ExamPaper{}).Error; err != nil {
+			return err
+		}
+		if err := deleteSessionPlans(tx, sessionIDs); err != nil {
+			return err
+		}
+		return tx.Where("id IN ?", sessionIDs).Delete(&models.ExamSession{}).Error
+	})
+	if errors.Is(err, ErrNotFound) {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("error deleting exam: %w", err)
+	}
+	return nil
+}
+
+func (s *gormExamStore) AddSession(session *models.ExamSession) error {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&models.Exam{}, session.ExamID).Error; err != nil {
+			return err
+		}
+		return tx.Create(session).Error
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("error adding exam session: %w", err)
+	}
+	return nil
+}
+
+func (s *gormExamStore) GetSession(id uint) (models.ExamSession, error) {
+	var session models.ExamSession
+	err := s.db.Preload("Papers", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).First(&session, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.ExamSession{}, ErrNotFound
+	}
+	if err != nil {
+		return models.ExamSession{}, fmt.Errorf("error fetching exam session: %w", err)
+	}
+	return session, nil
+}
+
+// DeleteSession removes a session with its papers and seating plans.
+func (s *gormExamStore) DeleteSession(examID, sessionID uint) error {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("exam_id = ?", examID).Delete(&models.ExamSession{}, sessionID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		if err := tx.Where("exam_session_id = ?", sessionID).Delete(&models.ExamPaper{}).Error; err != nil {
+			return err
+		}
+		return deleteSessionPlans(tx, []uint{sessionID})
+	})
+	if errors.Is(err, ErrNotFound) {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("error deleting exam session: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package db
+
+import (
+	"DevMaan707/UMS/models"
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+type RoomFilter struct {
+	IDs       []uint
+	Blocks    []string
+	RoomTypes []string
+}
+
+// RoomStore manages the rooms available for seating, grouped by block.
+type RoomStore interface {
+	CreateRooms(rooms []models.Room) error
+	ListRooms(filter RoomFilter) ([]models.Room, error)
+	GetRoom(id uint) (models.Room, error)
+	UpdateRoom(room *models.Room) error
+	DeleteRoom(id uint) error
+}
+
+type gormRoomStore struct {
+	db *gorm.DB
+}
+
+func NewRoomStore(db *gorm.DB) RoomStore {
+	return &gormRoomStore{db: db}
+}
+
+func (s *gormRoomStore) CreateRooms(rooms []models.Room) error {
+	if len(rooms) == 0 {
+		return nil
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := ensureNewRooms(tx, rooms, 0); err != nil {
+			return err
+		}
+		return tx.Create(&rooms).Error
+	})
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return fmt.Errorf("%w: room already exists in its block", ErrConflict)
+	}
+	if errors.Is(err, ErrConflict) {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("error creating rooms: %w", err)
+	}
+	return nil
+}
+
+// ensureNewRooms returns ErrConflict when a block would hold two rooms with
+// the same number, within rooms or against the stored rooms other than
+// exceptID.
+func ensureNewRooms(tx *gorm.DB, rooms []models.Room, exceptID uint) error {
+	seen := map[[2]string]bool{}
+	numbers := make([]string, 0, len(rooms))
+	var duplicates []string
+	for _, room := range rooms {
+		key := [2]string{room.Block, room.RoomNumber}
+		if seen[key] {
+			duplicates = append(duplicates, roomName(room.Block, room.RoomNumber))
+		}
+		seen[key] = true
+		numbers = append(numbers, room.RoomNumber)
+	}
+	if len(duplicates) > 0 {
+		return fmt.Errorf("%w: duplicate rooms %s", ErrConflict, strings.Join(duplicates, ", "))
+	}
+
+	var stored []models.Room
+	if err := tx.Where("room_number IN ? AND id <> ?", numbers, exceptID).Find(&stored).Error; err != nil {
+		return err
+	}
+	var existing []string
+	for _, room := range stored {
+		if seen[[2]string{room.Block, room.RoomNumber}] {
+			existing = append(existing, roomName(room.Block, room.RoomNumber))
+		}
+	}
+	if len(existing) > 0 {
+		return fmt.Errorf("%w: rooms %s", ErrConflict, strings.Join(existing, ", "))
+	}
+	return nil
+}
+
+func roomName(block, roomNumber string) string {
+	if block == "" {
+		return roomNumber
+	}
+	return block + "/" + roomNumber
+}
+
+func (s *gormRoomStore) ListRooms(filter RoomFilter) ([]models.Room, error) {
+	query := s.db.Model(&models.Room{})
+	if len(filter.IDs) > 0 {
+		query = query.Where("id IN ?", filter.IDs)
+	}
+	if len(filter.Blocks) > 0 {
+		query = query.Where("block IN ?", filter.Blocks)
+	}
+	if len(filter.RoomTypes) > 0 {
+		query = query.Where("room_type IN ?", filter.RoomTypes)
+	}
+
+	var rooms []models.Room
+	if err := query.Order("block").Order("room_number").Find(&rooms).Error; err != nil {
+		return nil, fmt.Errorf("error listing rooms: %w", err)
+	}
+	return rooms, nil
+}
+
+func (s *gormRoomStore) GetRoom(id uint) (models.Room, error) {
+	var room models.Room
+	err := s.db.First(&room, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.Room{}, ErrNotFound
+	}
+	if err != nil {
+		return models.Room{}, fmt.Errorf("error fetching room: %w", err)
+	}
+	return room, nil
+}
+
+func (s *gormRoomStore) UpdateRoom(room *models.Room) error {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := ensureNewRooms(tx, []models.Room{*room}, room.ID); err != nil {
+			return err
+		}
+		return tx.Save(room).Error
+	})
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return fmt.Errorf("%w: room already exists in its block", ErrConflict)
+	}
+	if errors.Is(err, ErrConflict) {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("error updating room: %w", err)
+	}
+	return nil
+}
+
+func (s *gormRoomStore) DeleteRoom(id uint) error {
+	result := s.db.Delete(&models.Room{}, id)
+	if result.Error != nil {
+		return fmt.Errorf("error deleting room: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
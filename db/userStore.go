@@ -0,0 +1,68 @@
+package db
+
+import (
+	"DevMaan707/UMS/models"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// UserStore keeps the accounts that can sign in to the API.
+type UserStore interface {
+	CreateUser(user *models.User) error
+	GetUser(id uint) (models.User, error)
+	GetUserByUsername(username string) (models.User, error)
+}
+
+type gormUserStore struct {
+	db *gorm.DB
+}
+
+func NewUserStore(db *gorm.DB) UserStore {
+	return &gormUserStore{db: db}
+}
+
+func (s *gormUserStore) CreateUser(user *models.User) error {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&models.User{}).Where("username = ?", user.Username).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return fmt.Errorf("%w: user %s", ErrConflict, user.Username)
+		}
+		return tx.Create(user).Error
+	})
+	if errors.Is(err, ErrConflict) {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("error creating user: %w", err)
+	}
+	return nil
+}
+
+func (s *gormUserStore) GetUser(id uint) (models.User, error) {
+	var user models.User
+	err := s.db.First(&user, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.User{}, ErrNotFound
+	}
+	if err != nil {
+		return models.User{}, fmt.Errorf("error fetching user: %w", err)
+	}
+	return user, nil
+}
+
+func (s *gormUserStore) GetUserByUsername(username string) (models.User, error) {
+	var user models.User
+	err := s.db.Where("username = ?", username).First(&user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.User{}, ErrNotFound
+	}
+	if err != nil {
+		return models.User{}, fmt.Errorf("error fetching user: %w", err)
+	}
+	return user, nil
+}
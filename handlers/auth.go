@@ -0,0 +1,234 @@
+package handlers
+
+import (
+	"DevMaan707/UMS/db"
+	"DevMaan707/UMS/helpers"
+	"DevMaan707/UMS/middleware"
+	"DevMaan707/UMS/models"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	userStore       db.UserStore
+	revocationStore db.RevocationStore
+	tokenConfig     helpers.TokenConfig
+)
+
+func SetUserStore(store db.UserStore) {
+	userStore = store
+}
+
+func SetRevocationStore(store db.RevocationStore) {
+	revocationStore = store
+}
+
+func SetTokenConfig(config helpers.TokenConfig) {
+	tokenConfig = config
+}
+
+func Login(c *gin.Context) {
+	var request struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	user, err := userStore.GetUserByUsername(helpers.NormalizeUsername(request.Username))
+	if err != nil && !errors.Is(err, db.ErrNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user"})
+		return
+	}
+	if err != nil || !helpers.CheckPassword(user.PasswordHash, request.Password) {
+		middleware.Unauthorized(c, "Invalid username or password")
+		return
+	}
+
+	issueTokens(c, user)
+}
+
+func RefreshToken(c *gin.Context) {
+	var request struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	claims, err := helpers.ParseToken(tokenConfig, request.RefreshToken, helpers.TokenTypeRefresh)
+	if err != nil {
+		middleware.Unauthorized(c, "Invalid refresh token")
+		return
+	}
+	revoked, err := revocationStore.IsRevoked(claims.ID, claims.UserID, claims.IssuedAt.Time)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check token"})
+		return
+	}
+	if revoked {
+		middleware.Unauthorized(c, "Invalid refresh token")
+		return
+	}
+	user, err := userStore.GetUser(claims.UserID)
+	if errors.Is(err, db.ErrNotFound) {
+		middleware.Unauthorized(c, "Invalid refresh token")
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user"})
+		return
+	}
+
+	// Refresh tokens are single use; the new pair replaces this one. Revoking
+	// is the check that counts: of two refreshes racing with the same token,
+	// only one can revoke it.
+	err = revocationStore.RevokeToken(claims.ID, claims.UserID, claims.ExpiresAt.Time, "refreshed")
+	if errors.Is(err, db.ErrConflict) {
+		middleware.Unauthorized(c, "Invalid refresh token")
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke token"})
+		return
+	}
+	issueTokens(c, user)
+}
+
+func issueTokens(c *gin.Context, user models.User) {
+	tokens, err := helpers.IssueTokens(tokenConfig, user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to issue tokens"})
+		return
+	}
+	c.JSON(http.StatusOK, tokens)
+}
+
+// Logout revokes the access token on the request and, if given, the
+// matching refresh token.
+func Logout(c *gin.Context) {
+	claims, found := middleware.CurrentClaims(c)
+	if !found {
+		middleware.Unauthorized(c, "Authorization token required")
+		return
+	}
+
+	var request struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+	}
+
+	if request.RefreshToken != "" {
+		refresh, err := helpers.ParseToken(tokenConfig, request.RefreshToken, helpers.TokenTypeRefresh)
+		if err != nil || refresh.UserID != claims.UserID {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid refresh token"})
+			return
+		}
+		// A refresh token that was already revoked stays revoked.
+		err = revocationStore.RevokeToken(refresh.ID, refresh.UserID, refresh.ExpiresAt.Time, "logout")
+		if err != nil && !errors.Is(err, db.ErrConflict) {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke token"})
+			return
+		}
+	}
+	if err := revocationStore.RevokeToken(claims.ID, claims.UserID, claims.ExpiresAt.Time, "logout"); err != nil && !errors.Is(err, db.ErrConflict) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out"})
+}
+
+// RevokeUserTokens signs a user out everywhere by revoking every token issued
+// to them so far.
+func RevokeUserTokens(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user id"})
+		return
+	}
+
+	_, err = userStore.GetUser(uint(id))
+	if errors.Is(err, db.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "User not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user"})
+		return
+	}
+
+	expiresAt := time.Now().Add(max(tokenConfig.AccessTTL, tokenConfig.RefreshTTL))
+	if err := revocationStore.RevokeUserTokens(uint(id), expiresAt, "revoked_by_admin"); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke tokens"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Tokens revoked"})
+}
+
+func CreateUser(c *gin.Context) {
+	var request struct {
+		Username  string `json:"username"`
+		Name      string `json:"name"`
+		Role      string `json:"role"`
+		StudentID string `json:"student_id"`
+		Password  string `json:"password"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	user := models.User{
+		Username: helpers.NormalizeUsername(request.Username),
+		Name:     request.Name,
+		Role:     strings.ToLower(strings.TrimSpace(request.Role)),
+	}
+	if user.Username == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "username is required"})
+		return
+	}
+	if !helpers.ValidRole(user.Role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role"})
+		return
+	}
+	if user.Role == helpers.RoleStudent {
+		user.StudentID = helpers.NormalizeStudentID(request.StudentID)
+		if _, err := helpers.ParseRollNumber(user.StudentID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	hash, err := helpers.HashPassword(request.Password)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	user.PasswordHash = hash
+
+	err = userStore.CreateUser(&user)
+	if errors.Is(err, db.ErrConflict) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"user": user})
+}
@@ -0,0 +1,218 @@
+package handlers
+
+import (
+	"DevMaan707/UMS/db"
+	"DevMaan707/UMS/helpers"
+	"DevMaan707/UMS/models"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+var classStore db.ClassStore
+
+func SetClassStore(store db.ClassStore) {
+	classStore = store
+}
+
+func CreateClass(c *gin.Context) {
+	var class models.Class
+	if err := c.ShouldBindJSON(&class); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	class.ID = 0
+	if err := helpers.NormalizeClass(&class); err != nil {
+		c.JSON(http.StatusBadRequest, classError(err, -1))
+		return
+	}
+
+	classes := []models.Class{class}
+	err := classStore.CreateClasses(classes)
+	if errors.Is(err, db.ErrConflict) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create class"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"class": classes[0]})
+}
+
+func ImportRoster(c *gin.Context) {
+	var classes []models.Class
+	if err := c.ShouldBindJSON(&classes); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	students := 0
+	for i := range classes {
+		classes[i].ID = 0
+		if err := helpers.NormalizeClass(&classes[i]); err != nil {
+			c.JSON(http.StatusBadRequest, classError(err, i))
+			return
+		}
+		students += len(classes[i].StudentIDs)
+	}
+
+	err := classStore.CreateClasses(classes)
+	if errors.Is(err, db.ErrConflict) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import roster"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":  "Roster imported",
+		"classes":  len(classes),
+		"students": students,
+	})
+}
+
+// classError describes a roster validation failure, listing malformed
+// student ids when there are any. index is the class's position in an
+// import, or -1 for a single class.
+func classError(err error, index int) gin.H {
+	body := gin.H{"error": err.Error()}
+	if index >= 0 {
+		body["index"] = index
+	}
+	var invalid *helpers.InvalidStudentIDsError
+	if errors.As(err, &invalid) {
+		body["invalid_student_ids"] = invalid.StudentIDs
+	}
+	return body
+}
+
+func ListClasses(c *gin.Context) {
+	filter := db.ClassFilter{
+		Branches: splitQuery(c.Query("branch")),
+		Sections: splitQuery(c.Query("section")),
+	}
+	for _, year := range splitQuery(c.Query("year")) {
+		value, err := strconv.Atoi(year)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid year"})
+			return
+		}
+		filter.Years = append(filter.Years, value)
+	}
+
+	classes, err := classStore.ListClasses(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch classes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"classes": classes})
+}
+
+func GetClass(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid class id"})
+		return
+	}
+
+	class, err := classStore.GetClass(uint(id))
+	if errors.Is(err, db.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Class not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch class"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"class": class})
+}
+
+func AddStudents(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid class id"})
+		return
+	}
+
+	var students []models.Student
+	if err := c.ShouldBindJSON(&students); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	for i := range students {
+		students[i].ID = 0
+		students[i].StudentID = helpers.NormalizeStudentID(students[i].StudentID)
+		if students[i].StudentID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "student_id is required", "index": i})
+			return
+		}
+		if _, err := helpers.ParseRollNumber(students[i].StudentID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "index": i})
+			return
+		}
+	}
+
+	class, err := classStore.AddStudents(uint(id), students)
+	if errors.Is(err, db.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Class not found"})
+		return
+	}
+	if errors.Is(err, db.ErrConflict) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add students"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"class": class})
+}
+
+func UpdateEligibility(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid class id"})
+		return
+	}
+
+	var request struct {
+		DetainedList []string `json:"detained_list"`
+		DebarredList []string `json:"debarred_list"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	class, err := classStore.GetClass(uint(id))
+	if errors.Is(err, db.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Class not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch class"})
+		return
+	}
+
+	class.DetainedList = request.DetainedList
+	class.DebarredList = request.DebarredList
+	if err := helpers.NormalizeEligibility(&class); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := classStore.UpdateEligibility(&class); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update class"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"class": class})
+}
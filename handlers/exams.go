@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"DevMaan707/UMS/db"
+	"DevMaan707/UMS/helpers"
+	"DevMaan707/UMS/models"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+var examStore db.ExamStore
+
+func SetExamStore(store db.ExamStore) {
+	examStore = store
+}
+
+func CreateExam(c *gin.Context) {
+	var exam models.Exam
+	if err := c.ShouldBindJSON(&exam); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	exam.ID = 0
+	if err := helpers.NormalizeExam(&exam); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := examStore.CreateExam(&exam); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create exam"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"exam": exam})
+}
+
+func ListExams(c *gin.Context) {
+	exams, err := examStore.ListExams()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch exams"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"exams": exams})
+}
+
+func GetExam(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid exam id"})
+		return
+	}
+
+	exam, err := examStore.GetExam(uint(id))
+	if errors.Is(err, db.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Exam not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch exam"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"exam": exam})
+}
+
+func UpdateExam(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid exam id"})
+		return
+	}
+
+	exam, err := examStore.GetExam(uint(id))
+	if errors.Is(err, db.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Exam not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch exam"})
+		return
+	}
+
+	var request struct {
+		ExamName string `json:"exam_name"`
+		ExamType string `json:"exam_type"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	update := models.Exam{ExamName: request.ExamName, ExamType: request.ExamType}
+	if err := helpers.NormalizeExam(&update); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	exam.ExamName = update.ExamName
+	exam.ExamType = update.ExamType
+
+	if err := examStore.UpdateExam(&exam); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update exam"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"exam": exam})
+}
+
+func DeleteExam(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid exam id"})
+		return
+	}
+
+	err = examStore.DeleteExam(uint(id))
+	if errors.Is(err, db.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Exam not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete exam"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Exam deleted"})
+}
+
+func AddExamSession(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid exam id"})
+		return
+	}
+
+	var session models.ExamSession
+	if err := c.ShouldBindJSON(&session); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	session.ID = 0
+	session.ExamID = uint(id)
+	if err := helpers.NormalizeSession(&session); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = examStore.AddSession(&session)
+	if errors.Is(err, db.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Exam not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add session"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"session": session})
+}
+
+func DeleteExamSession(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid exam id"})
+		return
+	}
+	sessionID, err := strconv.ParseUint(c.Param("session_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session id"})
+		return
+	}
+
+	err = examStore.DeleteSession(uint(id), uint(sessionID))
+	if errors.Is(err, db.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Session not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete session"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Session deleted"})
+}
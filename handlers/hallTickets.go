@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"DevMaan707/UMS/db"
+	"DevMaan707/UMS/helpers"
+	"DevMaan707/UMS/middleware"
+	"DevMaan707/UMS/models"
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetHallTicket renders a student's hall ticket for every upcoming exam.
+// Invigilators cannot see every seat on it, so they get no ticket.
+func GetHallTicket(c *gin.Context) {
+	studentID := helpers.NormalizeStudentID(c.Param("student_id"))
+	invigilatorID, ok := canViewSeat(c, studentID)
+	if !ok {
+		return
+	}
+	if invigilatorID != 0 {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+		return
+	}
+
+	now := time.Now()
+	examAssignments, err := assignmentStore.FindStudentAssignments(studentID, db.AssignmentFilter{EndsAfter: now})
+	if errors.Is(err, db.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "No upcoming exams"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch assignment"})
+		return
+	}
+
+	student := helpers.RosterEntry{StudentID: studentID}
+	students, err := classStore.FindStudents([]string{studentID})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch students"})
+		return
+	}
+	if len(students) > 0 {
+		student.Name = students[0].Name
+		student.Branch = students[0].Branch
+		student.Year = students[0].Year
+		student.Section = students[0].Section
+	}
+
+	ticket, err := helpers.NewHallTicket(tokenConfig, student, examAssignments, now)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sign hall ticket"})
+		return
+	}
+	var buf bytes.Buffer
+	if err := helpers.GenerateHallTicketPDF(&buf, ticket); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate PDF"})
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "hall-ticket-"+studentID+".pdf"))
+	c.Data(http.StatusOK, "application/pdf", buf.Bytes())
+}
+
+// VerifyHallTicket checks the payload scanned from a hall ticket's QR code
+// and returns the student's seats in the plans it names as they are stored
+// now, flagging plans that no longer seat the student. Invigilators see only
+// the seats in rooms they are on duty in.
+func VerifyHallTicket(c *gin.Context) {
+	caller, found := middleware.CurrentClaims(c)
+	if !found {
+		middleware.Unauthorized(c, "Authorization token required")
+		return
+	}
+	var request struct {
+		Payload string `json:"payload"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil || request.Payload == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	claims, err := helpers.ParseHallTicket(tokenConfig, request.Payload)
+	if errors.Is(err, helpers.ErrTokenExpired) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Hall ticket expired"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid hall ticket"})
+		return
+	}
+
+	examAssignments, err := assignmentStore.FindStudentAssignments(claims.StudentID, db.AssignmentFilter{})
+	if err != nil && !errors.Is(err, db.ErrNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch assignment"})
+		return
+	}
+	onTicket := map[uint]bool{}
+	for _, planID := range claims.Plans {
+		onTicket[planID] = true
+	}
+	seated := []models.ExamAssignment{}
+	for _, assignment := range examAssignments {
+		if onTicket[assignment.ID] {
+			seated = append(seated, assignment)
+			delete(onTicket, assignment.ID)
+		}
+	}
+	if !caller.Can(helpers.PermissionViewAnySeat) {
+		onDuty, err := assignmentStore.FindStudentAssignments(claims.StudentID, db.AssignmentFilter{InvigilatorID: caller.UserID})
+		if err != nil && !errors.Is(err, db.ErrNotFound) {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch assignment"})
+			return
+		}
+		seated = slices.DeleteFunc(seated, func(assignment models.ExamAssignment) bool {
+			return !slices.ContainsFunc(onDuty, func(duty models.ExamAssignment) bool { return duty.ID == assignment.ID })
+		})
+	}
+	// Plans left over were deleted or replaced, or no longer seat the student.
+	stalePlans := []uint{}
+	for _, planID := range claims.Plans {
+		if onTicket[planID] {
+			stalePlans = append(stalePlans, planID)
+		}
+	}
+
+	name := ""
+	students, err := classStore.FindStudents([]string{claims.StudentID})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch students"})
+		return
+	}
+	if len(students) > 0 {
+		name = students[0].Name
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"valid":       true,
+		"current":     len(stalePlans) == 0,
+		"student_id":  claims.StudentID,
+		"name":        name,
+		"expires_at":  claims.ExpiresAt.Time,
+		"seats":       helpers.StudentAssignments(seated),
+		"stale_plans": stalePlans,
+	})
+}
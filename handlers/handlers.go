@@ -1,48 +1,150 @@
 package handlers
 
 import (
+	"DevMaan707/UMS/db"
 	"DevMaan707/UMS/helpers"
+	"DevMaan707/UMS/middleware"
 	"DevMaan707/UMS/models"
+	"errors"
 	"net/http"
+	"slices"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
+var assignmentStore db.AssignmentStore
+
+// errPlanConflicts aborts a save whose plan clashes with one stored since
+// the plan was checked.
+var errPlanConflicts = errors.New("plan conflicts with stored plans")
+
+func SetAssignmentStore(store db.AssignmentStore) {
+	assignmentStore = store
+}
+
 func AssignRoomsForExams(c *gin.Context) {
+	assignRoomsForExams(c, false)
+}
+
+func PreviewExamPlan(c *gin.Context) {
+	assignRoomsForExams(c, true)
+}
+
+func assignRoomsForExams(c *gin.Context, preview bool) {
 	var params models.Params
 
 	if err := c.ShouldBindJSON(&params); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
 		return
 	}
+
+	var session models.ExamSession
+	if params.SessionID != 0 {
+		var err error
+		session, err = examStore.GetSession(params.SessionID)
+		if errors.Is(err, db.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"message": "Exam session not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch exam session"})
+			return
+		}
+
+		params.TOE = session.StartTime.Format(time.RFC3339)
+		params.DOE = session.Duration
+		branches, years := helpers.SessionScope(session)
+		if len(params.Branches) == 0 {
+			params.Branches = branches
+		}
+		if len(params.Years) == 0 {
+			params.Years = years
+		}
+	}
+	// A repeated branch or block would load its students or rooms twice.
+	params.Branches = helpers.UniqueStrings(params.Branches)
+	params.Blocks = helpers.UniqueStrings(params.Blocks)
+	papers := helpers.SessionPapers(session)
+	for key, paper := range params.Papers {
+		papers[key] = paper
+	}
+
 	toe, err := time.Parse(time.RFC3339, params.TOE)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Time of Exam format"})
 		return
 	}
 	doe, err := time.ParseDuration(params.DOE)
-	if err != nil {
+	if err != nil || doe <= 0 {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Duration of Exam format"})
 		return
 	}
-
-	blocks, classes := helpers.GenerateTestData()
+	if params.SingleChildPosition == "" {
+		params.SingleChildPosition = helpers.SingleChildAlternate
+	}
+	if params.SingleChildPosition != helpers.SingleChildAlternate && params.SingleChildPosition != helpers.SingleChildCenter {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid single child position"})
+		return
+	}
+	if params.Pattern == "" {
+		params.Pattern = helpers.PatternCheckerboard
+	}
+	if !helpers.ValidPattern(params.Pattern) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid seating pattern"})
+		return
+	}
+	for i, roomType := range params.RoomTypes {
+		params.RoomTypes[i] = helpers.NormalizeRoomType(roomType)
+		if _, found := helpers.LayoutFor(roomType); !found {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid room type " + roomType})
+			return
+		}
+	}
+	dryRun := preview || params.DryRun
 
 	selectedRooms := []helpers.Room{}
+	capacity := 0
 	for _, block := range params.Blocks {
-		if rooms, found := blocks[block]; found {
-			selectedRooms = append(selectedRooms, rooms...)
+		rooms, err := roomStore.ListRooms(db.RoomFilter{Blocks: []string{block}, RoomTypes: params.RoomTypes})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch rooms"})
+			return
+		}
+		for _, room := range rooms {
+			examRoom := helpers.NewRoom(room)
+			selectedRooms = append(selectedRooms, examRoom)
+			capacity += helpers.RoomCapacity(examRoom, params)
 		}
 	}
 
+	include := map[string]bool{}
+	for _, studentID := range params.IncludeStudents {
+		include[helpers.NormalizeStudentID(studentID)] = true
+	}
+
 	selectedStudents := make(map[string][]string)
+	studentPapers := make(map[string]string)
+	roster := []helpers.RosterEntry{}
+	skippedStudents := []helpers.SkippedStudent{}
 	for _, branch := range params.Branches {
-		if branchClasses, found := classes[branch]; found {
-			for _, class := range branchClasses {
-				if helpers.ContainsInt(params.Years, class.Year) {
-					selectedStudents[branch] = append(selectedStudents[branch], class.StudentIDs...)
-				}
+		classes, err := classStore.ListClasses(db.ClassFilter{Branches: []string{branch}, Years: params.Years, Sections: params.Sections})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch classes"})
+			return
+		}
+		for _, class := range classes {
+			if _, found := helpers.LookupPaper(papers, branch, class.Year); len(session.Papers) > 0 && !found {
+				continue
+			}
+
+			eligible, skipped := helpers.EligibleStudents(class, include)
+			selectedStudents[branch] = append(selectedStudents[branch], eligible...)
+			skippedStudents = append(skippedStudents, skipped...)
+			for _, studentID := range eligible {
+				studentPapers[studentID] = helpers.PaperFor(papers, branch, class.Year)
+				roster = append(roster, helpers.RosterEntry{StudentID: studentID, Branch: branch, Year: class.Year, Section: class.Section})
 			}
 		}
 	}
@@ -51,69 +153,281 @@ func AssignRoomsForExams(c *gin.Context) {
 		helpers.ShuffleStudents(selectedStudents)
 	}
 
-	assignments := helpers.GenerateExamAssignments("exam", selectedRooms, selectedStudents, params, toe, doe)
+	var plan helpers.SeatingPlan
+	var reasons []string
+	if len(params.Papers) > 0 || params.SeparatePapers {
+		plan, err = helpers.SolvePaperSeating(selectedRooms, selectedStudents, studentPapers, params, toe, doe)
+		// An infeasible plan still seats whoever fits; the unseated gate
+		// below decides whether it may be committed.
+		var infeasible *helpers.InfeasibleError
+		if errors.As(err, &infeasible) {
+			reasons = infeasible.Reasons
+		} else if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate assignments"})
+			return
+		}
+	} else {
+		plan = helpers.GenerateExamAssignments("exam", selectedRooms, selectedStudents, params, toe, doe)
+		if len(papers) > 0 {
+			helpers.LabelSubjects(plan, studentPapers)
+		}
+	}
+
+	helpers.AttachRoomStats(plan, selectedRooms, params, roster)
+	report := helpers.BuildCapacityReport(roster, selectedRooms, params, plan)
+	report.Reasons = reasons
+	if needed := max(len(report.Unseated), report.Shortfall); needed > 0 {
+		candidates, err := roomStore.ListRooms(db.RoomFilter{RoomTypes: params.RoomTypes})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch rooms"})
+			return
+		}
+		otherRooms := []helpers.Room{}
+		for _, room := range candidates {
+			if !slices.Contains(params.Blocks, room.Block) {
+				otherRooms = append(otherRooms, helpers.NewRoom(room))
+			}
+		}
+		report.SuggestedRooms = helpers.SuggestRooms(otherRooms, params, needed)
+	}
+
+	examAssignments := helpers.ToExamAssignments(plan)
+	helpers.AttachSession(examAssignments, session)
+	existing, err := assignmentStore.FetchExamAssignmentsInWindow(toe, toe.Add(doe))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch assignments"})
+		return
+	}
+	// Replacing swaps out the session's current plan, or without a session
+	// the sessionless plan at this time, so it does not clash with the new
+	// one. Other sessions' plans at the same time stay and are checked.
+	var replace *db.AssignmentFilter
+	if params.Replace {
+		replace = &db.AssignmentFilter{SessionID: session.ID, TOE: toe}
+		existing = slices.DeleteFunc(existing, replace.Matches)
+	}
+	conflicts := helpers.FindConflicts(existing, examAssignments)
+
+	if dryRun {
+		c.JSON(http.StatusOK, gin.H{
+			"message":     "Exam Room Assignment Preview",
+			"assignments": plan.Rooms,
+			"capacity":    capacity,
+			"report":      report,
+			"skipped":     skippedStudents,
+			"conflicts":   conflicts,
+		})
+		return
+	}
+	if len(conflicts) > 0 {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":     "Plan overlaps rooms or students already booked for another exam",
+			"conflicts": conflicts,
+		})
+		return
+	}
+	if len(report.Unseated) > 0 && !params.Force {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":  "Selected rooms leave students unseated; add rooms or set force to commit anyway",
+			"report": report,
+		})
+		return
+	}
+
+	err = assignmentStore.SaveExamAssignments(examAssignments, db.SaveOptions{
+		Replace: replace,
+		Check: func(stored []models.ExamAssignment) error {
+			if conflicts = helpers.FindConflicts(stored, examAssignments); len(conflicts) > 0 {
+				return errPlanConflicts
+			}
+			return nil
+		},
+	})
+	if errors.Is(err, errPlanConflicts) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":     "Plan overlaps rooms or students already booked for another exam",
+			"conflicts": conflicts,
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save assignments"})
+		return
+	}
+	for i := range examAssignments {
+		plan.Rooms[i].ID = examAssignments[i].ID
+	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"message":     "Exam Room Assignments",
-		"assignments": assignments,
+		"assignments": plan.Rooms,
+		"capacity":    capacity,
+		"report":      report,
+		"skipped":     skippedStudents,
 	})
 }
 
-func GetAllAssignments(c *gin.Context) {
+func assignmentFilter(c *gin.Context) (db.AssignmentFilter, bool) {
+	if sessionID := c.Query("session_id"); sessionID != "" {
+		id, err := strconv.ParseUint(sessionID, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session id"})
+			return db.AssignmentFilter{}, false
+		}
+		return db.AssignmentFilter{SessionID: uint(id)}, true
+	}
 
-	toeStr := c.Query("toe")
-	toe, err := time.Parse(time.RFC3339, toeStr)
+	toe, err := time.Parse(time.RFC3339, c.Query("toe"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Time of Exam format"})
+		return db.AssignmentFilter{}, false
+	}
+	return db.AssignmentFilter{TOE: toe}, true
+}
+
+func GetAllAssignments(c *gin.Context) {
+	filter, ok := assignmentFilter(c)
+	if !ok {
 		return
 	}
 
-	filteredAssignments, _ := helpers.FetchAssignmentsByTime(toe)
+	examAssignments, err := assignmentStore.FetchExamAssignments(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch assignments"})
+		return
+	}
 	c.JSON(http.StatusOK, gin.H{
-		"assignments": filteredAssignments,
+		"assignments": helpers.FromExamAssignments(examAssignments),
 	})
 }
 
-func GetStudentSpecificAssignment(c *gin.Context) {
-	toeStr := c.Query("toe")
-	studentID := c.Param("student_id")
-	toe, err := time.Parse(time.RFC3339, toeStr)
+// DeletePlan removes one room of a stored seating plan.
+func DeletePlan(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Time of Exam format"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid plan id"})
 		return
 	}
 
-	roomAssignment, err := helpers.GetStudentAssignment(studentID, toe)
+	err = assignmentStore.DeleteExamAssignment(uint(id))
+	if errors.Is(err, db.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Plan not found"})
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"message": "Assignment not found"})
-	} else {
-		c.JSON(http.StatusOK, gin.H{
-			"room_number": roomAssignment.RoomNumber,
-			"details":     roomAssignment.Details,
-			"toe":         roomAssignment.Toe,
-			"block":       roomAssignment.Block,
-		})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete plan"})
+		return
 	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Plan deleted"})
 }
-func GeneratePDFByTOE(c *gin.Context) {
-	toeStr := c.Query("toe")
-	toe, err := time.Parse(time.RFC3339, toeStr)
+
+// DeletePlans removes the whole seating plan of the session or time of exam
+// in the query.
+func DeletePlans(c *gin.Context) {
+	filter, ok := assignmentFilter(c)
+	if !ok {
+		return
+	}
+
+	deleted, err := assignmentStore.DeleteExamAssignments(filter)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Time of Exam format"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete plans"})
 		return
 	}
+	if deleted == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Plan not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Plans deleted", "deleted": deleted})
+}
+
+// AuditConflicts reports clashes between stored plans, optionally limited to
+// the from/to window given in the query.
+func AuditConflicts(c *gin.Context) {
+	var from, to time.Time
+	for name, bound := range map[string]*time.Time{"from": &from, "to": &to} {
+		value := c.Query(name)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + name + " time format"})
+			return
+		}
+		*bound = parsed
+	}
 
-	assignments, err := helpers.FetchAssignmentsByTime(toe)
+	examAssignments, err := assignmentStore.FetchExamAssignmentsInWindow(from, to)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch assignments"})
 		return
 	}
+	c.JSON(http.StatusOK, gin.H{
+		"conflicts": helpers.AuditConflicts(examAssignments),
+	})
+}
 
-	pdfPath, err := helpers.GeneratePDF(assignments)
+// canViewSeat checks that the caller may see the student's seats: staff may
+// see anyone's, students only their own and invigilators only those in rooms
+// they are on duty in. For invigilators it returns their user ID to scope the
+// lookup by, otherwise 0. It writes the error response itself.
+func canViewSeat(c *gin.Context, studentID string) (uint, bool) {
+	claims, found := middleware.CurrentClaims(c)
+	if !found {
+		middleware.Unauthorized(c, "Authorization token required")
+		return 0, false
+	}
+	if claims.Can(helpers.PermissionViewAnySeat) {
+		return 0, true
+	}
+	if claims.Can(helpers.PermissionViewOwnSeat) && claims.StudentID == studentID {
+		return 0, true
+	}
+	if claims.Can(helpers.PermissionViewOwnRooms) {
+		return claims.UserID, true
+	}
+	c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+	return 0, false
+}
+
+// GetStudentSpecificAssignment looks up a student's seat for the session or
+// time of exam given in the query, or every upcoming seat when neither is.
+func GetStudentSpecificAssignment(c *gin.Context) {
+	studentID := helpers.NormalizeStudentID(c.Param("student_id"))
+	invigilatorID, ok := canViewSeat(c, studentID)
+	if !ok {
+		return
+	}
+	upcoming := c.Query("session_id") == "" && c.Query("toe") == ""
+
+	filter := db.AssignmentFilter{EndsAfter: time.Now()}
+	if !upcoming {
+		if filter, ok = assignmentFilter(c); !ok {
+			return
+		}
+	}
+	filter.InvigilatorID = invigilatorID
+
+	examAssignments, err := assignmentStore.FindStudentAssignments(studentID, filter)
+	if errors.Is(err, db.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Assignment not found"})
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate PDF"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch assignment"})
 		return
 	}
 
-	c.File(pdfPath)
+	assignments := helpers.StudentAssignments(examAssignments)
+	if upcoming {
+		c.JSON(http.StatusOK, gin.H{
+			"student_id":  studentID,
+			"assignments": assignments,
+		})
+		return
+	}
+	c.JSON(http.StatusOK, assignments[0])
 }
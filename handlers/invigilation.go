@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"DevMaan707/UMS/db"
+	"DevMaan707/UMS/helpers"
+	"DevMaan707/UMS/middleware"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetInvigilators puts invigilators on duty in one room of a stored plan,
+// replacing whoever was there before.
+func SetInvigilators(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid plan id"})
+		return
+	}
+
+	var request struct {
+		UserIDs []uint `json:"user_ids"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	for _, userID := range request.UserIDs {
+		user, err := userStore.GetUser(userID)
+		if errors.Is(err, db.ErrNotFound) || (err == nil && user.Role != helpers.RoleInvigilator) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "User " + strconv.FormatUint(uint64(userID), 10) + " is not an invigilator"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user"})
+			return
+		}
+	}
+
+	err = assignmentStore.SetInvigilators(uint(id), request.UserIDs)
+	if errors.Is(err, db.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Plan not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to set invigilators"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Invigilators updated", "user_ids": request.UserIDs})
+}
+
+// GetInvigilatorRooms lists the rooms the signed-in invigilator is on duty
+// in, for the session or time of exam in the query or every upcoming exam.
+func GetInvigilatorRooms(c *gin.Context) {
+	claims, found := middleware.CurrentClaims(c)
+	if !found {
+		middleware.Unauthorized(c, "Authorization token required")
+		return
+	}
+
+	filter := db.AssignmentFilter{EndsAfter: time.Now()}
+	if c.Query("session_id") != "" || c.Query("toe") != "" {
+		var ok bool
+		if filter, ok = assignmentFilter(c); !ok {
+			return
+		}
+	}
+
+	examAssignments, err := assignmentStore.FetchInvigilatorAssignments(claims.UserID, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch assignments"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"assignments": helpers.FromExamAssignments(examAssignments),
+	})
+}
@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"DevMaan707/UMS/db"
+	"DevMaan707/UMS/helpers"
+	"DevMaan707/UMS/models"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+var pdfCache *helpers.PDFCache
+
+// SetPDFCache sets where rendered PDFs are kept for repeat downloads; nil
+// renders every request afresh.
+func SetPDFCache(cache *helpers.PDFCache) {
+	pdfCache = cache
+}
+
+// printablePlan is a stored plan loaded for printing with who sits in it.
+type printablePlan struct {
+	filter      db.AssignmentFilter
+	assignments []models.ExamAssignment
+	roster      map[string]helpers.RosterEntry
+}
+
+// GeneratePDFByTOE draws the seat map of each room of a stored plan.
+func GeneratePDFByTOE(c *gin.Context) {
+	plan, ok := loadPrintablePlan(c)
+	if !ok {
+		return
+	}
+
+	roomIDs := []uint{}
+	for _, assignment := range plan.assignments {
+		if assignment.RoomID != 0 {
+			roomIDs = append(roomIDs, uint(assignment.RoomID))
+		}
+	}
+	rooms := map[uint]helpers.Room{}
+	if len(roomIDs) > 0 {
+		stored, err := roomStore.ListRooms(db.RoomFilter{IDs: roomIDs})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch rooms"})
+			return
+		}
+		for _, room := range stored {
+			rooms[room.ID] = helpers.NewRoom(room)
+		}
+	}
+
+	servePDF(c, "seating-plan", plan, rooms, func(w io.Writer) error {
+		return helpers.GeneratePDF(w, plan.assignments, rooms, plan.roster)
+	})
+}
+
+// GenerateDoorNoticesPDF renders the notice pasted outside each room of a
+// stored plan, listing its roll numbers by branch and section.
+func GenerateDoorNoticesPDF(c *gin.Context) {
+	plan, ok := loadPrintablePlan(c)
+	if !ok {
+		return
+	}
+
+	servePDF(c, "door-notices", plan, nil, func(w io.Writer) error {
+		return helpers.GenerateDoorNoticePDF(w, helpers.BuildDoorNotices(plan.assignments, plan.roster))
+	})
+}
+
+// GenerateAttendancePDF renders the attendance register invigilators sign in
+// each room of a stored plan.
+func GenerateAttendancePDF(c *gin.Context) {
+	plan, ok := loadPrintablePlan(c)
+	if !ok {
+		return
+	}
+
+	servePDF(c, "attendance", plan, nil, func(w io.Writer) error {
+		return helpers.GenerateAttendancePDF(w, plan.assignments, plan.roster)
+	})
+}
+
+// servePDF renders a report in memory and sends it as a download. The
+// report's version is a hash of everything it is drawn from, so repeat
+// downloads of an unchanged plan are served from the cache, or answered with
+// 304 when the client already holds that version.
+func servePDF(c *gin.Context, report string, plan printablePlan, extra interface{}, render func(w io.Writer) error) {
+	inputs, err := json.Marshal([]interface{}{report, plan.assignments, plan.roster, extra})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate PDF"})
+		return
+	}
+	sum := sha256.Sum256(inputs)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	if c.GetHeader("If-None-Match") == etag {
+		c.Header("ETag", etag)
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	data, found := pdfCache.Get(etag)
+	if !found {
+		var buf bytes.Buffer
+		if err := render(&buf); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate PDF"})
+			return
+		}
+		data = buf.Bytes()
+		pdfCache.Put(etag, data)
+	}
+
+	c.Header("ETag", etag)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", planFileName(report, plan.filter)))
+	c.Data(http.StatusOK, "application/pdf", data)
+}
+
+// planFileName names a report after the plan it was drawn from, e.g.
+// seating-plan-session-12.pdf or attendance-20270110-1000.pdf.
+func planFileName(report string, filter db.AssignmentFilter) string {
+	if filter.SessionID != 0 {
+		return fmt.Sprintf("%s-session-%d.pdf", report, filter.SessionID)
+	}
+	return fmt.Sprintf("%s-%s.pdf", report, filter.TOE.UTC().Format("20060102-1504"))
+}
+
+// loadPrintablePlan loads the plan selected by the query along with the
+// students seated in it, writing the error response itself when it fails.
+func loadPrintablePlan(c *gin.Context) (printablePlan, bool) {
+	filter, ok := assignmentFilter(c)
+	if !ok {
+		return printablePlan{}, false
+	}
+
+	assignments, err := assignmentStore.FetchExamAssignments(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch assignments"})
+		return printablePlan{}, false
+	}
+	if len(assignments) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Plan not found"})
+		return printablePlan{}, false
+	}
+
+	roster, err := seatedRoster(assignments)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch students"})
+		return printablePlan{}, false
+	}
+	return printablePlan{filter: filter, assignments: assignments, roster: roster}, true
+}
+
+// seatedRoster looks up the name, branch, year and section of everyone
+// seated in the given room plans.
+func seatedRoster(assignments []models.ExamAssignment) (map[string]helpers.RosterEntry, error) {
+	var studentIDs []string
+	for _, assignment := range assignments {
+		for _, seat := range assignment.Seats {
+			studentIDs = append(studentIDs, seat.StudentID)
+		}
+	}
+
+	students, err := classStore.FindStudents(studentIDs)
+	if err != nil {
+		return nil, err
+	}
+	roster := make(map[string]helpers.RosterEntry, len(students))
+	for _, student := range students {
+		roster[student.StudentID] = helpers.RosterEntry{
+			StudentID: student.StudentID,
+			Name:      student.Name,
+			Branch:    student.Branch,
+			Year:      student.Year,
+			Section:   student.Section,
+		}
+	}
+	return roster, nil
+}
@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"DevMaan707/UMS/db"
+	"DevMaan707/UMS/helpers"
+	"DevMaan707/UMS/models"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+var roomStore db.RoomStore
+
+func SetRoomStore(store db.RoomStore) {
+	roomStore = store
+}
+
+func CreateRoom(c *gin.Context) {
+	var room models.Room
+	if err := c.ShouldBindJSON(&room); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	room.ID = 0
+	if err := helpers.NormalizeRoom(&room); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rooms := []models.Room{room}
+	err := roomStore.CreateRooms(rooms)
+	if errors.Is(err, db.ErrConflict) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create room"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"room": rooms[0]})
+}
+
+func ImportRooms(c *gin.Context) {
+	var rooms []models.Room
+	if err := c.ShouldBindJSON(&rooms); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	for i := range rooms {
+		rooms[i].ID = 0
+		if err := helpers.NormalizeRoom(&rooms[i]); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "index": i})
+			return
+		}
+	}
+
+	err := roomStore.CreateRooms(rooms)
+	if errors.Is(err, db.ErrConflict) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import rooms"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":  "Rooms imported",
+		"imported": len(rooms),
+	})
+}
+
+func ListRooms(c *gin.Context) {
+	filter := db.RoomFilter{
+		Blocks:    splitQuery(c.Query("block")),
+		RoomTypes: splitQuery(c.Query("room_type")),
+	}
+	for i, roomType := range filter.RoomTypes {
+		filter.RoomTypes[i] = helpers.NormalizeRoomType(roomType)
+	}
+
+	rooms, err := roomStore.ListRooms(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch rooms"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"rooms": rooms})
+}
+
+func GetRoom(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid room id"})
+		return
+	}
+
+	room, err := roomStore.GetRoom(uint(id))
+	if errors.Is(err, db.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Room not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch room"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"room": room})
+}
+
+func UpdateRoom(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid room id"})
+		return
+	}
+
+	existing, err := roomStore.GetRoom(uint(id))
+	if errors.Is(err, db.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Room not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch room"})
+		return
+	}
+
+	var room models.Room
+	if err := c.ShouldBindJSON(&room); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	room.ID = existing.ID
+	room.CreatedAt = existing.CreatedAt
+	if err := helpers.NormalizeRoom(&room); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = roomStore.UpdateRoom(&room)
+	if errors.Is(err, db.ErrConflict) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update room"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"room": room})
+}
+
+func DeleteRoom(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid room id"})
+		return
+	}
+
+	err = roomStore.DeleteRoom(uint(id))
+	if errors.Is(err, db.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Room not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete room"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Room deleted"})
+}
+
+func splitQuery(value string) []string {
+	if value == "" {
+		return nil
+	}
+	var values []string
+	for _, part := range strings.Split(value, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			values = append(values, part)
+		}
+	}
+	return values
+}
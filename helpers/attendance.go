@@ -0,0 +1,131 @@
+package helpers
+
+import (
+	"DevMaan707/UMS/models"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+type attendanceColumn struct {
+	title string
+	width float64
+	align string
+}
+
+var attendanceColumns = []attendanceColumn{
+	{"#", 10, "C"},
+	{"Seat", 28, "C"},
+	{"Roll number", 30, "C"},
+	{"Name", 52, "L"},
+	{"Booklet no.", 30, "C"},
+	{"Signature", 40, "C"},
+}
+
+const attendanceRowHeight = 10.0
+
+var sideOrder = map[string]int{"left": 0, "center": 1, "middle": 1, "right": 2}
+
+// attendanceOrder lists the seats of a room front to back, bench by bench, the
+// order an invigilator walks the room in.
+func attendanceOrder(seats []models.SeatAssignment) []models.SeatAssignment {
+	ordered := append([]models.SeatAssignment(nil), seats...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		if ordered[i].Row != ordered[j].Row {
+			return ordered[i].Row < ordered[j].Row
+		}
+		if ordered[i].Column != ordered[j].Column {
+			return ordered[i].Column < ordered[j].Column
+		}
+		return sideOrder[ordered[i].Side] < sideOrder[ordered[j].Side]
+	})
+	return ordered
+}
+
+func seatLabel(seat models.SeatAssignment) string {
+	label := fmt.Sprintf("R%d C%d", seat.Row, seat.Column)
+	if seat.Side != "" {
+		label += " " + seat.Side
+	}
+	return label
+}
+
+// GenerateAttendancePDF writes an attendance register for every room plan,
+// starting each room on a new page and repeating the room and column headers
+// on every page the room runs onto. Names come from the roster; students
+// missing from it are listed without one.
+func GenerateAttendancePDF(w io.Writer, assignments []models.ExamAssignment, roster map[string]RosterEntry) error {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetAutoPageBreak(false, 0)
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	_, pageHeight := pdf.GetPageSize()
+	_, _, _, bottomMargin := pdf.GetMargins()
+	bottom := pageHeight - bottomMargin - 10
+
+	for _, assignment := range assignments {
+		header := func(continued bool) {
+			pdf.AddPage()
+			title := "Attendance: Room " + assignment.RoomNumber
+			if continued {
+				title += " (continued)"
+			}
+			pdf.SetFont("Arial", "B", 16)
+			pdf.CellFormat(0, 10, title, "", 1, "C", false, 0, "")
+			pdf.SetFont("Arial", "", 11)
+			pdf.CellFormat(0, 6, RoomLocation(assignmentBlock(assignment), assignment.Floor, assignment.Building), "", 1, "C", false, 0, "")
+			pdf.CellFormat(0, 6, examWindow(assignment.TOE, assignment.EndTime), "", 1, "C", false, 0, "")
+			pdf.Ln(4)
+
+			pdf.SetFont("Arial", "B", 10)
+			pdf.SetFillColor(230, 230, 230)
+			for _, column := range attendanceColumns {
+				pdf.CellFormat(column.width, 8, column.title, "1", 0, "C", true, 0, "")
+			}
+			pdf.Ln(-1)
+			pdf.SetFont("Arial", "", 10)
+		}
+
+		header(false)
+		for i, seat := range attendanceOrder(assignment.Seats) {
+			if pdf.GetY()+attendanceRowHeight > bottom {
+				header(true)
+			}
+			name := roster[seat.StudentID].Name
+			values := []string{strconv.Itoa(i + 1), seatLabel(seat), seat.StudentID, tr(name), "", ""}
+			for j, column := range attendanceColumns {
+				pdf.CellFormat(column.width, attendanceRowHeight, fitText(pdf, values[j], column.width-2), "1", 0, column.align, false, 0, "")
+			}
+			pdf.Ln(-1)
+		}
+
+		// Totals and the invigilator's signature need about four rows.
+		if pdf.GetY()+4*attendanceRowHeight > bottom {
+			header(true)
+		}
+		pdf.Ln(4)
+		pdf.SetFont("Arial", "B", 11)
+		pdf.CellFormat(60, 8, fmt.Sprintf("Total: %d", len(assignment.Seats)), "", 0, "L", false, 0, "")
+		pdf.CellFormat(60, 8, "Present: ________", "", 0, "L", false, 0, "")
+		pdf.CellFormat(60, 8, "Absent: ________", "", 1, "L", false, 0, "")
+		pdf.Ln(6)
+		pdf.SetFont("Arial", "", 11)
+		pdf.CellFormat(95, 8, "Invigilator name: ______________________", "", 0, "L", false, 0, "")
+		pdf.CellFormat(95, 8, "Signature: ______________________", "", 1, "L", false, 0, "")
+	}
+
+	return pdf.Output(w)
+}
+
+// fitText shortens text with an ellipsis until it fits width.
+func fitText(pdf *gofpdf.Fpdf, text string, width float64) string {
+	if pdf.GetStringWidth(text) <= width {
+		return text
+	}
+	for len(text) > 0 && pdf.GetStringWidth(text+"...") > width {
+		text = text[:len(text)-1]
+	}
+	return text + "..."
+}
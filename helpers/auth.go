@@ -0,0 +1,233 @@
+package helpers
+
+import (
+	"DevMaan707/UMS/models"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	TokenTypeAccess     = "access"
+	TokenTypeRefresh    = "refresh"
+	TokenTypeHallTicket = "hall_ticket"
+)
+
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid token")
+	ErrTokenExpired = errors.New("token expired")
+)
+
+// TokenConfig holds the keys and lifetimes used to sign tokens. Access tokens
+// and hall tickets are signed with Algorithm: HS256 uses AccessSecret, RS256
+// signs with SigningKey under KeyID and verifies against Keys. Refresh tokens
+// are only read back by this server and always use HS256 with RefreshSecret;
+// the type claim keeps the kinds apart when the secrets are shared.
+type TokenConfig struct {
+	Algorithm     string
+	AccessSecret  []byte
+	RefreshSecret []byte
+	SigningKey    *rsa.PrivateKey
+	KeyID         string
+	Keys          KeySet
+	Issuer        string
+	Audience      string
+	AccessTTL     time.Duration
+	RefreshTTL    time.Duration
+}
+
+type Claims struct {
+	UserID      uint     `json:"user_id"`
+	Username    string   `json:"username"`
+	Role        string   `json:"role"`
+	Permissions []string `json:"permissions"`
+	StudentID   string   `json:"student_id,omitempty"`
+	TokenType   string   `json:"token_type"`
+	jwt.RegisteredClaims
+}
+
+func (c *Claims) Can(permission string) bool {
+	return HasPermission(c.Permissions, permission)
+}
+
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+}
+
+func HashPassword(password string) (string, error) {
+	if len(password) < 8 {
+		return "", fmt.Errorf("password must be at least 8 characters")
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("error hashing password: %w", err)
+	}
+	return string(hash), nil
+}
+
+func CheckPassword(hash, password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+func NormalizeUsername(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
+
+func (c TokenConfig) algorithm(tokenType string) string {
+	if tokenType != TokenTypeRefresh && c.Algorithm == AlgorithmRS256 {
+		return AlgorithmRS256
+	}
+	return AlgorithmHS256
+}
+
+func (c TokenConfig) secret(tokenType string) []byte {
+	if tokenType == TokenTypeRefresh {
+		return c.RefreshSecret
+	}
+	return c.AccessSecret
+}
+
+// keyFunc only hands out a key when the token uses the algorithm expected for
+// its type, so an HS256 token can never be checked against an RSA public key.
+func (c TokenConfig) keyFunc(tokenType string) jwt.Keyfunc {
+	return func(token *jwt.Token) (interface{}, error) {
+		algorithm := c.algorithm(tokenType)
+		if token.Method.Alg() != algorithm {
+			return nil, fmt.Errorf("unexpected signing method %v", token.Header["alg"])
+		}
+		if algorithm == AlgorithmRS256 {
+			if c.Keys == nil {
+				return nil, fmt.Errorf("no RS256 key set configured")
+			}
+			kid, _ := token.Header["kid"].(string)
+			return c.Keys.PublicKey(kid)
+		}
+		return c.secret(tokenType), nil
+	}
+}
+
+func newTokenID() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", fmt.Errorf("error generating token id: %w", err)
+	}
+	return hex.EncodeToString(id), nil
+}
+
+func (c TokenConfig) sign(user models.User, tokenType string, ttl time.Duration, now time.Time) (string, error) {
+	tokenID, err := newTokenID()
+	if err != nil {
+		return "", err
+	}
+	claims := Claims{
+		UserID:      user.ID,
+		Username:    user.Username,
+		Role:        user.Role,
+		Permissions: PermissionsFor(user.Role),
+		StudentID:   user.StudentID,
+		TokenType:   tokenType,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenID,
+			Subject:   strconv.FormatUint(uint64(user.ID), 10),
+			Issuer:    c.Issuer,
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+	}
+	if c.Audience != "" {
+		claims.Audience = jwt.ClaimStrings{c.Audience}
+	}
+	return c.signClaims(tokenType, claims)
+}
+
+func (c TokenConfig) signClaims(tokenType string, claims jwt.Claims) (string, error) {
+	var signed string
+	var err error
+	if c.algorithm(tokenType) == AlgorithmRS256 {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		token.Header["kid"] = c.KeyID
+		signed, err = token.SignedString(c.SigningKey)
+	} else {
+		signed, err = jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(c.secret(tokenType))
+	}
+	if err != nil {
+		return "", fmt.Errorf("error signing %s token: %w", tokenType, err)
+	}
+	return signed, nil
+}
+
+// IssueTokens signs a fresh access and refresh token for user.
+func IssueTokens(config TokenConfig, user models.User) (TokenPair, error) {
+	now := time.Now()
+	access, err := config.sign(user, TokenTypeAccess, config.AccessTTL, now)
+	if err != nil {
+		return TokenPair{}, err
+	}
+	refresh, err := config.sign(user, TokenTypeRefresh, config.RefreshTTL, now)
+	if err != nil {
+		return TokenPair{}, err
+	}
+	return TokenPair{
+		AccessToken:  access,
+		RefreshToken: refresh,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(config.AccessTTL.Seconds()),
+	}, nil
+}
+
+// ParseToken verifies a token of the given type and returns its claims. The
+// token must carry exp and jti, and iss and aud must match when configured.
+// An expired token is reported as ErrTokenExpired, any other failure as
+// ErrInvalidToken.
+func ParseToken(config TokenConfig, tokenString, tokenType string) (*Claims, error) {
+	claims := &Claims{}
+	if err := config.parseClaims(tokenString, tokenType, claims); err != nil {
+		return nil, err
+	}
+	if claims.TokenType != tokenType || claims.ID == "" || claims.IssuedAt == nil {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+func (c TokenConfig) parseClaims(tokenString, tokenType string, claims jwt.Claims) error {
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{c.algorithm(tokenType)}),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	}
+	// Hall tickets carry only what fits their QR code; the pinned algorithm
+	// and key already tie them to this server.
+	if c.Issuer != "" && tokenType != TokenTypeHallTicket {
+		options = append(options, jwt.WithIssuer(c.Issuer))
+	}
+	if c.Audience != "" && tokenType != TokenTypeHallTicket {
+		options = append(options, jwt.WithAudience(c.Audience))
+	}
+
+	token, err := jwt.ParseWithClaims(tokenString, claims, c.keyFunc(tokenType), options...)
+	if errors.Is(err, jwt.ErrTokenExpired) {
+		return ErrTokenExpired
+	}
+	if err != nil || token == nil || !token.Valid {
+		return ErrInvalidToken
+	}
+	return nil
+}
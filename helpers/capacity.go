@@ -0,0 +1,109 @@
+package helpers
+
+import (
+	"DevMaan707/UMS/models"
+	"sort"
+)
+
+type RosterEntry struct {
+	StudentID string
+	Name      string
+	Branch    string
+	Year      int
+	Section   string
+}
+
+type CapacityRequirement struct {
+	Branch   string `json:"branch"`
+	Year     int    `json:"year"`
+	Required int    `json:"required"`
+	Seated   int    `json:"seated"`
+	Unseated int    `json:"unseated"`
+}
+
+type UnseatedStudent struct {
+	StudentID string `json:"student_id"`
+	Branch    string `json:"branch"`
+	Year      int    `json:"year"`
+}
+
+type CapacityReport struct {
+	Requirements   []CapacityRequirement `json:"requirements"`
+	RequiredSeats  int                   `json:"required_seats"`
+	AvailableSeats int                   `json:"available_seats"`
+	Shortfall      int                   `json:"shortfall"`
+	Unseated       []UnseatedStudent     `json:"unseated"`
+	SuggestedRooms []Room                `json:"suggested_rooms"`
+	Reasons        []string              `json:"reasons,omitempty"`
+}
+
+// BuildCapacityReport compares the roster against the seats handed out in
+// the plan, per branch and year, and lists everyone left without a seat.
+func BuildCapacityReport(roster []RosterEntry, rooms []Room, params models.Params, plan SeatingPlan) CapacityReport {
+	seated := plan.Seated()
+
+	report := CapacityReport{Unseated: []UnseatedStudent{}, SuggestedRooms: []Room{}}
+	for _, room := range rooms {
+		report.AvailableSeats += RoomCapacity(room, params)
+	}
+
+	type key struct {
+		branch string
+		year   int
+	}
+	requirements := map[key]*CapacityRequirement{}
+	for _, entry := range roster {
+		k := key{entry.Branch, entry.Year}
+		requirement, found := requirements[k]
+		if !found {
+			requirement = &CapacityRequirement{Branch: entry.Branch, Year: entry.Year}
+			requirements[k] = requirement
+		}
+		requirement.Required++
+		report.RequiredSeats++
+		if seated[entry.StudentID] {
+			requirement.Seated++
+			continue
+		}
+		requirement.Unseated++
+		report.Unseated = append(report.Unseated, UnseatedStudent{
+			StudentID: entry.StudentID,
+			Branch:    entry.Branch,
+			Year:      entry.Year,
+		})
+	}
+
+	for _, requirement := range requirements {
+		report.Requirements = append(report.Requirements, *requirement)
+	}
+	sort.Slice(report.Requirements, func(i, j int) bool {
+		if report.Requirements[i].Branch != report.Requirements[j].Branch {
+			return report.Requirements[i].Branch < report.Requirements[j].Branch
+		}
+		return report.Requirements[i].Year < report.Requirements[j].Year
+	})
+
+	if report.RequiredSeats > report.AvailableSeats {
+		report.Shortfall = report.RequiredSeats - report.AvailableSeats
+	}
+	return report
+}
+
+// SuggestRooms picks rooms from candidates, largest first, until they add
+// enough seats for the students still unseated.
+func SuggestRooms(candidates []Room, params models.Params, needed int) []Room {
+	sorted := append([]Room(nil), candidates...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return RoomCapacity(sorted[i], params) > RoomCapacity(sorted[j], params)
+	})
+
+	suggested := []Room{}
+	for _, room := range sorted {
+		if needed <= 0 {
+			break
+		}
+		suggested = append(suggested, room)
+		needed -= RoomCapacity(room, params)
+	}
+	return suggested
+}
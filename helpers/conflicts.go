@@ -0,0 +1,111 @@
+package helpers
+
+import (
+	"DevMaan707/UMS/models"
+	"sort"
+	"time"
+)
+
+const (
+	ConflictRoom    = "room"
+	ConflictStudent = "student"
+)
+
+type ConflictWindow struct {
+	ExamAssignmentID uint      `json:"exam_assignment_id,omitempty"`
+	ExamSessionID    uint      `json:"exam_session_id,omitempty"`
+	Block            string    `json:"block,omitempty"`
+	RoomNumber       string    `json:"room_number"`
+	Start            time.Time `json:"start"`
+	End              time.Time `json:"end"`
+}
+
+// Conflict is a room or student booked into two exams whose windows overlap.
+type Conflict struct {
+	Type       string         `json:"type"`
+	RoomNumber string         `json:"room_number,omitempty"`
+	StudentID  string         `json:"student_id,omitempty"`
+	First      ConflictWindow `json:"first"`
+	Second     ConflictWindow `json:"second"`
+}
+
+func conflictWindow(assignment models.ExamAssignment) ConflictWindow {
+	return ConflictWindow{
+		ExamAssignmentID: assignment.ID,
+		ExamSessionID:    assignment.ExamSessionID,
+		Block:            assignmentBlock(assignment),
+		RoomNumber:       assignment.RoomNumber,
+		Start:            assignment.TOE,
+		End:              assignment.EndTime,
+	}
+}
+
+func overlaps(a, b models.ExamAssignment) bool {
+	return a.TOE.Before(b.EndTime) && b.TOE.Before(a.EndTime)
+}
+
+// sameRoom compares plans by room ID. Plans stored without one fall back to
+// the block and room number, since room numbers repeat across blocks.
+func sameRoom(a, b models.ExamAssignment) bool {
+	if a.RoomID != 0 && b.RoomID != 0 {
+		return a.RoomID == b.RoomID
+	}
+	return assignmentBlock(a) == assignmentBlock(b) && a.RoomNumber == b.RoomNumber
+}
+
+func pairConflicts(first, second models.ExamAssignment) []Conflict {
+	if !overlaps(first, second) {
+		return nil
+	}
+
+	conflicts := []Conflict{}
+	if sameRoom(first, second) {
+		conflicts = append(conflicts, Conflict{
+			Type:       ConflictRoom,
+			RoomNumber: first.RoomNumber,
+			First:      conflictWindow(first),
+			Second:     conflictWindow(second),
+		})
+	}
+
+	students := map[string]bool{}
+	for _, seat := range first.Seats {
+		students[seat.StudentID] = true
+	}
+	for _, seat := range second.Seats {
+		if students[seat.StudentID] {
+			conflicts = append(conflicts, Conflict{
+				Type:      ConflictStudent,
+				StudentID: seat.StudentID,
+				First:     conflictWindow(first),
+				Second:    conflictWindow(second),
+			})
+		}
+	}
+	return conflicts
+}
+
+// FindConflicts checks a proposed plan against the plans already stored.
+func FindConflicts(existing, proposed []models.ExamAssignment) []Conflict {
+	conflicts := []Conflict{}
+	for _, stored := range existing {
+		for _, planned := range proposed {
+			conflicts = append(conflicts, pairConflicts(stored, planned)...)
+		}
+	}
+	return conflicts
+}
+
+// AuditConflicts checks every pair of stored plans for clashes.
+func AuditConflicts(assignments []models.ExamAssignment) []Conflict {
+	sorted := append([]models.ExamAssignment(nil), assignments...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].TOE.Before(sorted[j].TOE) })
+
+	conflicts := []Conflict{}
+	for i := range sorted {
+		for j := i + 1; j < len(sorted) && sorted[j].TOE.Before(sorted[i].EndTime); j++ {
+			conflicts = append(conflicts, pairConflicts(sorted[i], sorted[j])...)
+		}
+	}
+	return conflicts
+}
@@ -0,0 +1,240 @@
+package helpers
+
+import (
+	"DevMaan707/UMS/models"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// DoorNotice lists who sits in one room, for pasting outside its door.
+type DoorNotice struct {
+	Room     string            `json:"room"`
+	Location string            `json:"location"`
+	TOE      time.Time         `json:"toe"`
+	End      time.Time         `json:"end"`
+	Groups   []DoorNoticeGroup `json:"groups"`
+	Total    int               `json:"total"`
+}
+
+// DoorNoticeGroup is one branch and section of a room with its roll numbers
+// collapsed into ranges such as "23EG105D01–D30".
+type DoorNoticeGroup struct {
+	Branch  string   `json:"branch"`
+	Year    int      `json:"year,omitempty"`
+	Section string   `json:"section,omitempty"`
+	Ranges  []string `json:"ranges"`
+	Count   int      `json:"count"`
+}
+
+func (g DoorNoticeGroup) title() string {
+	parts := []string{g.Branch}
+	if g.Year != 0 {
+		parts = append(parts, "Year "+strconv.Itoa(g.Year))
+	}
+	if g.Section != "" {
+		parts = append(parts, "Section "+g.Section)
+	}
+	return strings.Join(parts, " - ")
+}
+
+// BuildDoorNotices groups the seats of each room plan by branch and section.
+// Students missing from the roster are grouped by the branch and section
+// their roll number encodes.
+func BuildDoorNotices(assignments []models.ExamAssignment, roster map[string]RosterEntry) []DoorNotice {
+	notices := make([]DoorNotice, 0, len(assignments))
+	for _, assignment := range assignments {
+		type groupKey struct {
+			branch  string
+			year    int
+			section string
+		}
+		byGroup := map[groupKey][]string{}
+		for _, seat := range assignment.Seats {
+			var key groupKey
+			if entry, found := roster[seat.StudentID]; found {
+				key = groupKey{entry.Branch, entry.Year, entry.Section}
+			} else if roll, err := ParseRollNumber(seat.StudentID); err == nil {
+				key = groupKey{roll.BranchCode, 0, roll.Section}
+			}
+			if key.branch == "" {
+				key.branch = "Other"
+			}
+			byGroup[key] = append(byGroup[key], seat.StudentID)
+		}
+
+		groups := make([]DoorNoticeGroup, 0, len(byGroup))
+		for key, studentIDs := range byGroup {
+			groups = append(groups, DoorNoticeGroup{
+				Branch:  key.branch,
+				Year:    key.year,
+				Section: key.section,
+				Ranges:  CollapseRollNumbers(studentIDs),
+				Count:   len(studentIDs),
+			})
+		}
+		sort.Slice(groups, func(i, j int) bool {
+			if groups[i].Branch != groups[j].Branch {
+				return groups[i].Branch < groups[j].Branch
+			}
+			if groups[i].Year != groups[j].Year {
+				return groups[i].Year < groups[j].Year
+			}
+			return groups[i].Section < groups[j].Section
+		})
+
+		notices = append(notices, DoorNotice{
+			Room:     assignment.RoomNumber,
+			Location: RoomLocation(assignmentBlock(assignment), assignment.Floor, assignment.Building),
+			TOE:      assignment.TOE,
+			End:      assignment.EndTime,
+			Groups:   groups,
+			Total:    len(assignment.Seats),
+		})
+	}
+	return notices
+}
+
+type rollSerial struct {
+	studentID string
+	prefix    string
+	suffix    string
+	index     int
+	ordered   bool
+}
+
+// rollRun is a run of consecutive roll numbers under one prefix.
+type rollRun struct {
+	first, last rollSerial
+	count       int
+}
+
+// rollNumberRuns sorts student IDs and splits them into runs of consecutive
+// serials under the same prefix. Serials run 01..99 then A0..A9, B0.. as in
+// 23EG105DA0. IDs that do not parse are runs of their own.
+func rollNumberRuns(studentIDs []string) []rollRun {
+	serials := make([]rollSerial, 0, len(studentIDs))
+	for _, studentID := range studentIDs {
+		serial := rollSerial{studentID: studentID, prefix: studentID}
+		if roll, err := ParseRollNumber(studentID); err == nil && roll.Serial != "" {
+			serial.prefix = strings.TrimSuffix(studentID, roll.Serial)
+			serial.suffix = roll.Serial
+			if strings.HasSuffix(serial.prefix, roll.Section) {
+				serial.suffix = roll.Section + roll.Serial
+			}
+			serial.index, serial.ordered = serialIndex(roll.Serial)
+		}
+		serials = append(serials, serial)
+	}
+	sort.Slice(serials, func(i, j int) bool {
+		if serials[i].prefix != serials[j].prefix {
+			return serials[i].prefix < serials[j].prefix
+		}
+		if serials[i].ordered && serials[j].ordered {
+			return serials[i].index < serials[j].index
+		}
+		return serials[i].studentID < serials[j].studentID
+	})
+
+	runs := []rollRun{}
+	for start := 0; start < len(serials); {
+		end := start
+		for end+1 < len(serials) {
+			current, next := serials[end], serials[end+1]
+			if !current.ordered || !next.ordered || current.prefix != next.prefix || next.index != current.index+1 {
+				break
+			}
+			end++
+		}
+		runs = append(runs, rollRun{first: serials[start], last: serials[end], count: end - start + 1})
+		start = end + 1
+	}
+	return runs
+}
+
+// CollapseRollNumbers joins runs of consecutive roll numbers, e.g.
+// 23EG105D01..23EG105D30 becomes "23EG105D01–D30".
+func CollapseRollNumbers(studentIDs []string) []string {
+	runs := rollNumberRuns(studentIDs)
+	ranges := make([]string, 0, len(runs))
+	for _, run := range runs {
+		if run.count == 1 {
+			ranges = append(ranges, run.first.studentID)
+		} else {
+			ranges = append(ranges, run.first.studentID+"–"+run.last.suffix)
+		}
+	}
+	return ranges
+}
+
+// serialIndex numbers a serial so that consecutive serials differ by one.
+// Numeric serials count as themselves; a letter followed by digits continues
+// after the largest numeric serial of the same width, so 99 is followed by A0.
+func serialIndex(serial string) (int, bool) {
+	if n, err := strconv.Atoi(serial); err == nil {
+		return n, true
+	}
+	if len(serial) < 2 || serial[0] < 'A' || serial[0] > 'Z' {
+		return 0, false
+	}
+	letter := serial[0]
+	n, err := strconv.Atoi(serial[1:])
+	if err != nil {
+		return 0, false
+	}
+	base := 1
+	for range serial[1:] {
+		base *= 10
+	}
+	return base*10 + int(letter-'A')*base + n, true
+}
+
+// GenerateDoorNoticePDF writes one door notice per page to w.
+func GenerateDoorNoticePDF(w io.Writer, notices []DoorNotice) error {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	for _, notice := range notices {
+		pdf.AddPage()
+
+		pdf.SetFont("Arial", "B", 28)
+		pdf.CellFormat(0, 16, "Room "+notice.Room, "", 1, "C", false, 0, "")
+		pdf.SetFont("Arial", "", 12)
+		pdf.CellFormat(0, 7, notice.Location, "", 1, "C", false, 0, "")
+		pdf.CellFormat(0, 7, examWindow(notice.TOE, notice.End), "", 1, "C", false, 0, "")
+		pdf.Ln(6)
+
+		for _, group := range notice.Groups {
+			pdf.SetFont("Arial", "B", 14)
+			pdf.CellFormat(0, 9, tr(fmt.Sprintf("%s (%d)", group.title(), group.Count)), "B", 1, "L", false, 0, "")
+			pdf.SetFont("Arial", "", 13)
+			pdf.MultiCell(0, 7, tr(strings.Join(group.Ranges, ",  ")), "", "L", false)
+			pdf.Ln(4)
+		}
+
+		pdf.SetFont("Arial", "B", 14)
+		pdf.CellFormat(0, 10, fmt.Sprintf("Total: %d students", notice.Total), "T", 1, "R", false, 0, "")
+	}
+
+	return pdf.Output(w)
+}
+
+// displayZone is the time zone printed documents show exam times in.
+var displayZone = time.UTC
+
+// SetTimeZone sets the time zone printed documents show exam times in.
+func SetTimeZone(zone *time.Location) {
+	displayZone = zone
+}
+
+func examWindow(toe, end time.Time) string {
+	if end.IsZero() {
+		return toe.In(displayZone).Format("02 Jan 2006, 15:04 MST")
+	}
+	return toe.In(displayZone).Format("02 Jan 2006, 15:04") + " to " + end.In(displayZone).Format("15:04 MST")
+}
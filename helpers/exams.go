@@ -0,0 +1,119 @@
+package helpers
+
+import (
+	"DevMaan707/UMS/models"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	ExamTypeMid           = "mid"
+	ExamTypeSemester      = "semester"
+	ExamTypeSupplementary = "supplementary"
+)
+
+func NormalizeExam(exam *models.Exam) error {
+	exam.ExamName = strings.TrimSpace(exam.ExamName)
+	exam.ExamType = strings.ToLower(strings.TrimSpace(exam.ExamType))
+
+	if exam.ExamName == "" {
+		return fmt.Errorf("exam_name is required")
+	}
+	switch exam.ExamType {
+	case ExamTypeMid, ExamTypeSemester, ExamTypeSupplementary:
+	default:
+		return fmt.Errorf("exam_type must be one of %s, %s or %s", ExamTypeMid, ExamTypeSemester, ExamTypeSupplementary)
+	}
+
+	for i := range exam.Sessions {
+		exam.Sessions[i].ID = 0
+		if err := NormalizeSession(&exam.Sessions[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func NormalizeSession(session *models.ExamSession) error {
+	if session.StartTime.IsZero() {
+		return fmt.Errorf("start_time is required for every session")
+	}
+	session.StartTime = session.StartTime.UTC()
+
+	duration, err := time.ParseDuration(session.Duration)
+	if err != nil || duration <= 0 {
+		return fmt.Errorf("invalid duration %q for session at %s", session.Duration, session.StartTime.Format(time.RFC3339))
+	}
+	session.Duration = duration.String()
+
+	seen := map[string]bool{}
+	for i := range session.Papers {
+		paper := &session.Papers[i]
+		paper.ID = 0
+		paper.Branch = strings.TrimSpace(paper.Branch)
+		paper.SubjectCode = strings.ToUpper(strings.TrimSpace(paper.SubjectCode))
+		paper.SubjectName = strings.TrimSpace(paper.SubjectName)
+
+		if paper.Branch == "" || paper.Year <= 0 || paper.SubjectCode == "" {
+			return fmt.Errorf("papers need a branch, year and subject_code")
+		}
+		key := paperKey(paper.Branch, paper.Year)
+		if seen[key] {
+			return fmt.Errorf("session at %s lists two papers for %s", session.StartTime.Format(time.RFC3339), key)
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+func paperKey(branch string, year int) string {
+	return fmt.Sprintf("%s:%d", branch, year)
+}
+
+// SessionPapers returns a session's papers keyed the way PaperFor expects.
+func SessionPapers(session models.ExamSession) map[string]string {
+	papers := map[string]string{}
+	for _, paper := range session.Papers {
+		papers[paperKey(paper.Branch, paper.Year)] = paper.SubjectCode
+	}
+	return papers
+}
+
+// SessionScope lists the branches and years that write a paper in session,
+// in the order the papers were entered.
+func SessionScope(session models.ExamSession) ([]string, []int) {
+	branches := []string{}
+	years := []int{}
+	for _, paper := range session.Papers {
+		if !slices.Contains(branches, paper.Branch) {
+			branches = append(branches, paper.Branch)
+		}
+		if !slices.Contains(years, paper.Year) {
+			years = append(years, paper.Year)
+		}
+	}
+	return branches, years
+}
+
+func AttachSession(examAssignments []models.ExamAssignment, session models.ExamSession) {
+	for i := range examAssignments {
+		examAssignments[i].ExamID = session.ExamID
+		examAssignments[i].ExamSessionID = session.ID
+		for j := range examAssignments[i].Seats {
+			examAssignments[i].Seats[j].ExamSessionID = session.ID
+		}
+	}
+}
+
+// LabelSubjects records each seated student's paper on their seat.
+func LabelSubjects(plan SeatingPlan, studentPapers map[string]string) {
+	for _, room := range plan.Rooms {
+		for i, seat := range room.Assignments {
+			if paper, found := studentPapers[seat.StudentID]; found {
+				room.Assignments[i].Subject = paper
+			}
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package helpers
+
+import (
+	"DevMaan707/UMS/models"
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/jung-kurt/gofpdf"
+	"github.com/skip2/go-qrcode"
+)
+
+// hallTicketGrace keeps a hall ticket valid for a day after its last exam.
+const hallTicketGrace = 24 * time.Hour
+
+const hallTicketRowHeight = 10.0
+
+// HallTicketClaims is the payload signed into a hall ticket's QR code. It
+// names the student and the stored room plans that seat them and nothing
+// more, so it fits the code however many exams the ticket covers; a verifier
+// looks the seats up. It is signed like an access token, so with RS256 it can
+// be checked against the published key set, but having no token type keeps
+// it from being used as one.
+type HallTicketClaims struct {
+	StudentID string `json:"student_id"`
+	Plans     []uint `json:"plans"`
+	jwt.RegisteredClaims
+}
+
+// HallTicket is what is printed for one student: every seat and the signed
+// payload encoded in the QR code.
+type HallTicket struct {
+	Student   RosterEntry
+	Seats     []StudentAssignmentResponse
+	Payload   string
+	ExpiresAt time.Time
+}
+
+// NewHallTicket signs the room plans seating a student. The ticket expires a
+// day after the last exam ends.
+func NewHallTicket(config TokenConfig, student RosterEntry, examAssignments []models.ExamAssignment, now time.Time) (HallTicket, error) {
+	tokenID, err := newTokenID()
+	if err != nil {
+		return HallTicket{}, err
+	}
+
+	ticket := HallTicket{Student: student, Seats: StudentAssignments(examAssignments)}
+	claims := HallTicketClaims{StudentID: student.StudentID}
+	lastEnd := now
+	for _, assignment := range examAssignments {
+		claims.Plans = append(claims.Plans, assignment.ID)
+		if assignment.EndTime.After(lastEnd) {
+			lastEnd = assignment.EndTime
+		}
+	}
+	ticket.ExpiresAt = lastEnd.Add(hallTicketGrace)
+
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		ID:        tokenID,
+		ExpiresAt: jwt.NewNumericDate(ticket.ExpiresAt),
+	}
+	if ticket.Payload, err = config.signClaims(TokenTypeHallTicket, claims); err != nil {
+		return HallTicket{}, err
+	}
+	return ticket, nil
+}
+
+// ParseHallTicket verifies the payload scanned from a hall ticket.
+func ParseHallTicket(config TokenConfig, payload string) (*HallTicketClaims, error) {
+	claims := &HallTicketClaims{}
+	if err := config.parseClaims(payload, TokenTypeHallTicket, claims); err != nil {
+		return nil, err
+	}
+	if claims.StudentID == "" || claims.ID == "" || len(claims.Plans) == 0 {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+type hallTicketColumn struct {
+	title string
+	width float64
+}
+
+var hallTicketColumns = []hallTicketColumn{
+	{"Date", 26},
+	{"Time", 30},
+	{"Subject", 40},
+	{"Room", 20},
+	{"Location", 44},
+	{"Seat", 30},
+}
+
+// GenerateHallTicketPDF writes a student's hall ticket to w: their details,
+// a QR code of the signed payload and a row per exam.
+func GenerateHallTicketPDF(w io.Writer, ticket HallTicket) error {
+	qr, err := qrcode.Encode(ticket.Payload, qrcode.Medium, 512)
+	if err != nil {
+		return fmt.Errorf("error encoding hall ticket QR code: %w", err)
+	}
+
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetAutoPageBreak(false, 0)
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	_, pageHeight := pdf.GetPageSize()
+	_, _, _, bottomMargin := pdf.GetMargins()
+	bottom := pageHeight - bottomMargin - 10
+	pdf.RegisterImageOptionsReader("qr", gofpdf.ImageOptions{ImageType: "PNG"}, bytes.NewReader(qr))
+
+	header := func(first bool) {
+		pdf.AddPage()
+		pdf.SetFont("Arial", "B", 20)
+		pdf.CellFormat(0, 12, "HALL TICKET", "", 1, "C", false, 0, "")
+		if first {
+			pdf.Image("qr", 155, 24, 45, 45, false, "", 0, "")
+
+			details := [][2]string{
+				{"Roll number", ticket.Student.StudentID},
+				{"Name", ticket.Student.Name},
+				{"Branch", ticket.Student.Branch},
+			}
+			if ticket.Student.Year != 0 {
+				details = append(details, [2]string{"Year", strconv.Itoa(ticket.Student.Year)})
+			}
+			if ticket.Student.Section != "" {
+				details = append(details, [2]string{"Section", ticket.Student.Section})
+			}
+			pdf.Ln(6)
+			for _, detail := range details {
+				pdf.SetFont("Arial", "B", 12)
+				pdf.CellFormat(32, 8, detail[0]+":", "", 0, "L", false, 0, "")
+				pdf.SetFont("Arial", "", 12)
+				pdf.CellFormat(100, 8, tr(detail[1]), "", 1, "L", false, 0, "")
+			}
+			pdf.SetY(max(pdf.GetY(), 24+45) + 6)
+		} else {
+			pdf.SetFont("Arial", "", 11)
+			pdf.CellFormat(0, 6, ticket.Student.StudentID+" (continued)", "", 1, "C", false, 0, "")
+			pdf.Ln(4)
+		}
+
+		pdf.SetFont("Arial", "B", 10)
+		pdf.SetFillColor(230, 230, 230)
+		for _, column := range hallTicketColumns {
+			pdf.CellFormat(column.width, 8, column.title, "1", 0, "C", true, 0, "")
+		}
+		pdf.Ln(-1)
+		pdf.SetFont("Arial", "", 10)
+	}
+
+	header(true)
+	for _, seat := range ticket.Seats {
+		if pdf.GetY()+hallTicketRowHeight > bottom {
+			header(false)
+		}
+		start, err := time.Parse(time.RFC3339, seat.Toe)
+		if err != nil {
+			return fmt.Errorf("error reading exam time for room %s: %w", seat.RoomNumber, err)
+		}
+		start = start.In(displayZone)
+		timing := start.Format("15:04")
+		if !seat.End.IsZero() {
+			timing += "-" + seat.End.In(displayZone).Format("15:04")
+		}
+		values := []string{
+			start.Format("02 Jan 2006"),
+			timing + " " + start.Format("MST"),
+			tr(seat.Subject),
+			seat.RoomNumber,
+			tr(RoomLocation(seat.Block, seat.Floor, seat.Building)),
+			fmt.Sprintf("R%d C%d %s", seat.Row, seat.Column, seat.Side),
+		}
+		for i, column := range hallTicketColumns {
+			pdf.CellFormat(column.width, hallTicketRowHeight, fitText(pdf, values[i], column.width-2), "1", 0, "C", false, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+
+	if pdf.GetY()+20 > bottom {
+		header(false)
+	}
+	pdf.Ln(6)
+	pdf.SetFont("Arial", "I", 9)
+	pdf.MultiCell(0, 5, "Bring this ticket and your college ID card to every exam. Invigilators scan the QR code to verify the seats above. Valid until "+ticket.ExpiresAt.In(displayZone).Format("02 Jan 2006, 15:04 MST")+".", "", "L", false)
+
+	return pdf.Output(w)
+}
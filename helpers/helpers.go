@@ -2,323 +2,139 @@ package helpers
 
 import (
 	"DevMaan707/UMS/models"
-	"bufio"
-	"encoding/json"
 	"fmt"
 	"math/rand"
-	"os"
-	"strconv"
 	"strings"
 	"time"
-
-	"github.com/jung-kurt/gofpdf"
 )
 
-type Room struct {
-	ID            uint   `json:"id"`
-	RoomType      string `json:"room_type"`
-	Capacity      int    `json:"capacity"`
-	RoomNumber    string `json:"room_number"`
-	AssignedClass string `json:"assigned_class"`
-	Rows          int    `json:"rows"`
-	Columns       int    `json:"columns"`
-}
-
-type Class struct {
-	ID         uint     `json:"id"`
-	ClassName  string   `json:"class_name"`
-	Year       int      `json:"year"`
-	Branch     string   `json:"branch"`
-	StudentIDs []string `json:"student_ids"`
-}
+func NormalizeClass(class *models.Class) error {
+	class.Branch = strings.TrimSpace(class.Branch)
+	class.Section = strings.ToUpper(strings.TrimSpace(class.Section))
+	class.ClassName = strings.TrimSpace(class.ClassName)
 
-func LogAssignments(assignments []map[string]interface{}, logFileName string, toe time.Time) error {
-	file, err := os.OpenFile(logFileName, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		return fmt.Errorf("error opening log file: %w", err)
+	if class.Branch == "" {
+		return fmt.Errorf("branch is required")
+	}
+	if class.Year <= 0 {
+		return fmt.Errorf("year must be positive for class %s", class.Branch)
 	}
-	defer file.Close()
-	logEntry := map[string]interface{}{
-		"time":        toe.Format(time.RFC3339),
-		"room":        assignments[0]["room"],
-		"assignments": assignments,
+	if class.ClassName == "" {
+		class.ClassName = fmt.Sprintf("%s-%s", class.Branch, class.Section)
 	}
 
-	entryData, err := json.Marshal(logEntry)
-	if err != nil {
-		return fmt.Errorf("error marshalling log entry to JSON: %w", err)
+	students := make([]models.Student, 0, len(class.Students)+len(class.StudentIDs))
+	listed := map[string]bool{}
+	for _, student := range class.Students {
+		student.StudentID = NormalizeStudentID(student.StudentID)
+		if student.StudentID == "" {
+			return fmt.Errorf("student_id is required in class %s", class.ClassName)
+		}
+		if listed[student.StudentID] {
+			return fmt.Errorf("student %s is listed twice in class %s", student.StudentID, class.ClassName)
+		}
+		listed[student.StudentID] = true
+		students = append(students, student)
+	}
+	for _, studentID := range class.StudentIDs {
+		studentID = NormalizeStudentID(studentID)
+		if studentID == "" || listed[studentID] {
+			continue
+		}
+		listed[studentID] = true
+		students = append(students, models.Student{StudentID: studentID})
 	}
 
-	if _, err := file.Write(append(entryData, '\n')); err != nil {
-		return fmt.Errorf("error writing to log file: %w", err)
+	class.StudentIDs = make([]string, 0, len(students))
+	for i := range students {
+		students[i].ID = 0
+		students[i].Branch = class.Branch
+		students[i].Year = class.Year
+		students[i].Section = class.Section
+		class.StudentIDs = append(class.StudentIDs, students[i].StudentID)
 	}
+	class.Students = students
 
-	return nil
+	if err := ValidateStudentIDs(class.ClassName, class.StudentIDs); err != nil {
+		return err
+	}
+	return NormalizeEligibility(class)
 }
 
-func GenerateTestData() (map[string][]Room, map[string][]Class) {
-	const numRows = 8
-	const numColumns = 3
-
-	branches := map[string]struct {
-		Name   string
-		Prefix map[int]string
-	}{
-		"A": {"CSE", map[int]string{1: "24EG105", 2: "23EG105", 3: "22EG105", 4: "21EG105"}},
-		"B": {"AIML", map[int]string{1: "24EG106", 2: "23EG106", 3: "22EG106", 4: "21EG106"}},
-		"C": {"CS", map[int]string{1: "24EG107", 2: "23EG107", 3: "22EG107", 4: "21EG107"}},
-		"D": {"ECE", map[int]string{1: "24EG108", 2: "23EG108", 3: "22EG108", 4: "21EG108"}},
+func NormalizeEligibility(class *models.Class) error {
+	roster := map[string]bool{}
+	for _, studentID := range class.StudentIDs {
+		roster[studentID] = true
 	}
 
-	blocks := map[string][]Room{}
-	classes := map[string][]Class{}
-
-	for block, branchData := range branches {
-		blockRooms := []Room{}
-		branchClasses := []Class{}
-
-		for i := 1; i <= 5; i++ {
-			roomType := "classroom"
-			roomNumber := fmt.Sprintf("%s-%02d", block, i)
-			capacity := numRows * numColumns * 2
-
-			room := Room{
-				ID:         uint(len(blockRooms) + 1),
-				RoomType:   roomType,
-				Capacity:   capacity,
-				RoomNumber: roomNumber,
-				Rows:       numRows,
-				Columns:    numColumns,
+	normalize := func(list []string, name string) ([]string, error) {
+		normalized := make([]string, 0, len(list))
+		seen := map[string]bool{}
+		for _, studentID := range list {
+			studentID = NormalizeStudentID(studentID)
+			if studentID == "" || seen[studentID] {
+				continue
 			}
-			blockRooms = append(blockRooms, room)
-		}
-
-		blocks[block] = blockRooms
-
-		sections := []string{"A", "B", "C", "D", "E"}
-		for year := 1; year <= 4; year++ {
-			for _, section := range sections {
-				className := fmt.Sprintf("%s-%s", branchData.Name, section)
-				studentIDs := generateStudentIDs(branchData.Prefix[year], section, rand.Intn(12)+55)
-
-				class := Class{
-					ID:         uint(len(branchClasses) + 1),
-					ClassName:  className,
-					Year:       year,
-					Branch:     branchData.Name,
-					StudentIDs: studentIDs,
-				}
-				branchClasses = append(branchClasses, class)
+			if !roster[studentID] {
+				return nil, fmt.Errorf("%s student %s is not in class %s", name, studentID, class.ClassName)
 			}
+			seen[studentID] = true
+			normalized = append(normalized, studentID)
 		}
-
-		classes[branchData.Name] = branchClasses
+		return normalized, nil
 	}
 
-	return blocks, classes
-}
-
-func generateStudentIDs(prefix, section string, capacity int) []string {
-	studentIDs := []string{}
-	for i := 1; i <= capacity; i++ {
-		studentID := fmt.Sprintf("%s%s%02d", prefix, section, i)
-		studentIDs = append(studentIDs, studentID)
+	var err error
+	if class.DetainedList, err = normalize(class.DetainedList, "detained"); err != nil {
+		return err
 	}
-	return studentIDs
-}
-
-func GenerateExamAssignments(assignType string, rooms []Room, students map[string][]string, params models.Params, toe time.Time, doe time.Duration) []map[string]interface{} {
-	assignments := make([]map[string]interface{}, 0)
-	roomIndex := 0
-
-	remainingStudents := map[string]int{}
-	for branch, studentList := range students {
-		remainingStudents[branch] = len(studentList)
+	if class.DebarredList, err = normalize(class.DebarredList, "debarred"); err != nil {
+		return err
 	}
+	return nil
+}
 
-	for roomIndex < len(rooms) && len(remainingStudents) > 0 {
-		room := rooms[roomIndex]
-
-		totalBenches := room.Rows * room.Columns
-		assignedStudents := []map[string]interface{}{}
-		benchIndex := 0
-		sections := map[string]int{}
-		years := map[int]int{}
-		totalStudents := 0
-
-		if params.NumberOfBranchesInRoom == 1 {
-			currentBranch := ""
-			for _, branch := range params.Branches {
-				if remainingStudents[branch] > 0 {
-					currentBranch = branch
-					break
-				}
-			}
-
-			if currentBranch == "" {
-				break
-			}
-
-			for benchIndex < totalBenches && remainingStudents[currentBranch] > 0 {
-				var row, column int
-				if params.RowWise {
-					row = (benchIndex / room.Columns) + 1
-					column = (benchIndex % room.Columns) + 1
-				} else {
-					column = (benchIndex / room.Rows) + 1
-					row = (benchIndex % room.Rows) + 1
-				}
-
-				if remainingStudents[currentBranch] > 0 {
-					studentID := students[currentBranch][0]
-					students[currentBranch] = students[currentBranch][1:]
-					remainingStudents[currentBranch]--
-
-					year := extractYear(studentID)
-					section := extractSection(studentID)
-
-					years[year]++
-					sections[section]++
-					totalStudents++
-
-					assignedStudents = append(assignedStudents, map[string]interface{}{
-						"student_id": studentID,
-						"row":        row,
-						"column":     column,
-						"side":       "left",
-						"toe":        toe.Format(time.RFC3339),
-						"doe":        doe.String(),
-					})
-				}
-
-				if remainingStudents[currentBranch] > 0 {
-					studentID := students[currentBranch][0]
-					students[currentBranch] = students[currentBranch][1:]
-					remainingStudents[currentBranch]--
-
-					year := extractYear(studentID)
-					section := extractSection(studentID)
-
-					years[year]++
-					sections[section]++
-					totalStudents++
-
-					assignedStudents = append(assignedStudents, map[string]interface{}{
-						"student_id": studentID,
-						"row":        row,
-						"column":     column,
-						"side":       "right",
-						"toe":        toe.Format(time.RFC3339),
-						"doe":        doe.String(),
-					})
-				}
-
-				benchIndex++
-			}
-		} else if params.NumberOfBranchesInRoom == 2 && !params.SingleChild {
-			branchIndex := 0
-			branchOrder := params.Branches
-
-			for benchIndex < totalBenches && len(remainingStudents) > 0 {
-				for branchIndex < len(branchOrder) && remainingStudents[branchOrder[branchIndex]] == 0 {
-					branchIndex++
-				}
-
-				if branchIndex >= len(branchOrder) {
-					break
-				}
-
-				branchA := branchOrder[branchIndex]
-				var row, column int
-				if params.RowWise {
-					row = (benchIndex / room.Columns) + 1
-					column = (benchIndex % room.Columns) + 1
-				} else {
-					column = (benchIndex / room.Rows) + 1
-					row = (benchIndex % room.Rows) + 1
-				}
-
-				if remainingStudents[branchA] > 0 {
-					studentID := students[branchA][0]
-					students[branchA] = students[branchA][1:]
-					remainingStudents[branchA]--
-
-					year := extractYear(studentID)
-					section := extractSection(studentID)
-
-					years[year]++
-					sections[section]++
-					totalStudents++
-
-					assignedStudents = append(assignedStudents, map[string]interface{}{
-						"student_id": studentID,
-						"row":        row,
-						"column":     column,
-						"side":       "left",
-						"toe":        toe.Format(time.RFC3339),
-						"doe":        doe.String(),
-					})
-				}
-				branchIndex++
-				if branchIndex >= len(branchOrder) {
-					branchIndex = 0
-				}
-				for branchIndex < len(branchOrder) && remainingStudents[branchOrder[branchIndex]] == 0 {
-					branchIndex++
-				}
-
-				if branchIndex < len(branchOrder) {
-					branchB := branchOrder[branchIndex]
-
-					if remainingStudents[branchB] > 0 {
-						studentID := students[branchB][0]
-						students[branchB] = students[branchB][1:]
-						remainingStudents[branchB]--
-
-						year := extractYear(studentID)
-						section := extractSection(studentID)
-
-						years[year]++
-						sections[section]++
-						totalStudents++
-
-						assignedStudents = append(assignedStudents, map[string]interface{}{
-							"student_id": studentID,
-							"row":        row,
-							"column":     column,
-							"side":       "right",
-							"toe":        toe.Format(time.RFC3339),
-							"doe":        doe.String(),
-						})
-					}
-				}
+const (
+	SkipReasonDetained = "detained"
+	SkipReasonDebarred = "debarred_attendance"
+)
 
-				benchIndex++
-			}
-		}
+type SkippedStudent struct {
+	StudentID string `json:"student_id"`
+	ClassName string `json:"class_name"`
+	Reason    string `json:"reason"`
+}
 
-		roomIndex++
-		assignments = append(assignments, map[string]interface{}{
-			"room":        room.RoomNumber,
-			"assignments": assignedStudents,
-		})
+// EligibleStudents splits a class roster into the students who sit the exam
+// and those skipped as detained or debarred. IDs in include override both
+// lists for a single exam.
+func EligibleStudents(class models.Class, include map[string]bool) ([]string, []SkippedStudent) {
+	reasons := map[string]string{}
+	for _, studentID := range class.DebarredList {
+		reasons[studentID] = SkipReasonDebarred
 	}
-	logFileName := "exam_assignments.log"
-	err := LogAssignments(assignments, logFileName, toe)
-	if err != nil {
-		fmt.Printf("Failed to log assignments: %v\n", err)
+	for _, studentID := range class.DetainedList {
+		reasons[studentID] = SkipReasonDetained
 	}
 
-	return assignments
-}
-
-func extractYear(studentID string) int {
-	year, _ := strconv.Atoi(string(studentID[0:2]))
-	return year
+	eligible := make([]string, 0, len(class.StudentIDs))
+	skipped := []SkippedStudent{}
+	for _, studentID := range class.StudentIDs {
+		if reason, found := reasons[studentID]; found && !include[studentID] {
+			skipped = append(skipped, SkippedStudent{
+				StudentID: studentID,
+				ClassName: class.ClassName,
+				Reason:    reason,
+			})
+			continue
+		}
+		eligible = append(eligible, studentID)
+	}
+	return eligible, skipped
 }
 
-func extractSection(studentID string) string {
-	return string(studentID[2])
+func NormalizeStudentID(studentID string) string {
+	return strings.ToUpper(strings.TrimSpace(studentID))
 }
 
 type ExamAssignment struct {
@@ -357,6 +173,19 @@ func ShuffleStudents(students map[string][]string) {
 	}
 }
 
+// UniqueStrings drops repeated values, keeping the first of each in order.
+func UniqueStrings(values []string) []string {
+	seen := map[string]bool{}
+	unique := make([]string, 0, len(values))
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			unique = append(unique, value)
+		}
+	}
+	return unique
+}
+
 func ContainsInt(slice []int, value int) bool {
 	for _, v := range slice {
 		if v == value {
@@ -365,140 +194,64 @@ func ContainsInt(slice []int, value int) bool {
 	}
 	return false
 }
-func FetchAssignmentsByTime(targetTime time.Time) ([]map[string]interface{}, error) {
-	file, err := os.Open("exam_assignments.log")
-	if err != nil {
-		return nil, fmt.Errorf("error opening log file: %w", err)
-	}
-	defer file.Close()
-
-	var assignments []map[string]interface{}
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		var logEntry map[string]interface{}
-		err := json.Unmarshal([]byte(line), &logEntry)
-		if err != nil {
-			return nil, fmt.Errorf("error unmarshalling JSON: %w", err)
-		}
-		if logTimeStr, exists := logEntry["time"]; exists {
-			logTime, err := time.Parse(time.RFC3339, logTimeStr.(string))
-			if err == nil && logTime.Equal(targetTime) {
-				if assignmentArray, exists := logEntry["assignments"]; exists {
-					if assignmentsList, ok := assignmentArray.([]interface{}); ok {
-						for _, assign := range assignmentsList {
-							if assignmentMap, ok := assign.(map[string]interface{}); ok {
-								assignments = append(assignments, assignmentMap)
-							}
-						}
-					}
-				}
-			}
-		}
-	}
-
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("error reading from file: %w", err)
-	}
-
-	return assignments, nil
-}
 
+// StudentAssignmentResponse is a student's seat for one exam. RoomNumber,
+// Details, Toe and Block keep the shape of the original lookup response.
 type StudentAssignmentResponse struct {
-	RoomNumber string `json:"room_number"`
-	Details    string `json:"details"`
-	Toe        string `json:"toe"`
-	Block      string `json:"block"`
+	StudentID     string    `json:"student_id"`
+	ExamID        uint      `json:"exam_id,omitempty"`
+	ExamSessionID uint      `json:"exam_session_id,omitempty"`
+	RoomNumber    string    `json:"room_number"`
+	Block         string    `json:"block"`
+	Floor         int       `json:"floor"`
+	Building      string    `json:"building,omitempty"`
+	Row           int       `json:"row"`
+	Column        int       `json:"column"`
+	Side          string    `json:"side"`
+	Subject       string    `json:"subject,omitempty"`
+	Details       string    `json:"details"`
+	Toe           string    `json:"toe"`
+	End           time.Time `json:"end"`
+	DOE           string    `json:"doe"`
 }
 
-func GetStudentAssignment(studentID string, toe time.Time) (StudentAssignmentResponse, error) {
-	assignments, err := FetchAssignmentsByTime(toe)
-	if err != nil {
-		return StudentAssignmentResponse{}, err
+func NewStudentAssignmentResponse(assignment models.ExamAssignment, seat models.SeatAssignment) StudentAssignmentResponse {
+	return StudentAssignmentResponse{
+		StudentID:     seat.StudentID,
+		ExamID:        assignment.ExamID,
+		ExamSessionID: assignment.ExamSessionID,
+		RoomNumber:    assignment.RoomNumber,
+		Block:         assignmentBlock(assignment),
+		Floor:         assignment.Floor,
+		Building:      assignment.Building,
+		Row:           seat.Row,
+		Column:        seat.Column,
+		Side:          seat.Side,
+		Subject:       seat.Subject,
+		Details:       fmt.Sprintf("%s - Row: %d", seat.Side, seat.Row),
+		Toe:           assignment.TOE.Format(time.RFC3339),
+		End:           assignment.EndTime,
+		DOE:           assignment.DOE,
 	}
-	for _, assignment := range assignments {
-		if innerAssignments, exists := assignment["assignments"]; exists {
-			if assignmentList, ok := innerAssignments.([]interface{}); ok {
-				for _, assign := range assignmentList {
-					assignmentData, ok := assign.(map[string]interface{})
-					if !ok {
-						continue
-					}
-					if assignmentData["student_id"] == studentID {
-						if assignmentToeStr, exists := assignmentData["toe"]; exists {
-							if assignmentToeStr == toe.Format(time.RFC3339) {
-								row, rowOk := assignmentData["row"].(float64)
-								side, sideOk := assignmentData["side"].(string)
+}
 
-								if rowOk && sideOk {
-									response := StudentAssignmentResponse{
-										RoomNumber: assignment["room"].(string),
-										Details:    fmt.Sprintf("%s - Row: %d", side, int(row)),
-										Toe:        assignmentToeStr.(string),
-										Block:      "",
-									}
-									return response, nil
-								} else {
-									return StudentAssignmentResponse{}, fmt.Errorf("invalid row or side format for student %s", studentID)
-								}
-							}
-						} else {
-							return StudentAssignmentResponse{}, fmt.Errorf("toe field not found for student %s", studentID)
-						}
-					}
-				}
-			}
-		}
+// assignmentBlock falls back to the room number for plans saved before the
+// block was stored with them.
+func assignmentBlock(assignment models.ExamAssignment) string {
+	if assignment.Block != "" {
+		return assignment.Block
 	}
-
-	return StudentAssignmentResponse{}, fmt.Errorf("no assignment found for student %s at time %s", studentID, toe.Format(time.RFC3339))
+	return BlockFromRoomNumber(assignment.RoomNumber)
 }
-func GeneratePDF(assignments []map[string]interface{}) (string, error) {
-	pdf := gofpdf.New("P", "mm", "A4", "")
-	pdf.SetFont("Arial", "B", 16)
-
-	shiftRight := 8.0
 
-	for _, assignment := range assignments {
-		roomNumber := assignment["room"].(string)
-		pdf.AddPage()
-
-		pdf.CellFormat(0, 10, "Room: "+roomNumber, "", 1, "C", false, 0, "")
-
-		if assignmentList, ok := assignment["assignments"].([]interface{}); ok {
-			const (
-				benchWidth  = 60.0
-				benchHeight = 15.0
-				padding     = 5.0
-			)
-
-			for _, assign := range assignmentList {
-				assignMap := assign.(map[string]interface{})
-				studentID := assignMap["student_id"].(string)
-				row := assignMap["row"].(float64)
-				column := assignMap["column"].(float64)
-				side := assignMap["side"].(string)
-
-				xPosition := shiftRight + (benchWidth+padding)*(column-1)
-				yPosition := float64(row) * 20
-
-				pdf.Rect(xPosition, yPosition, benchWidth, benchHeight, "D")
-				pdf.SetFont("Arial", "B", 10)
-
-				if side == "left" {
-					pdf.Text(xPosition+5, yPosition+10, studentID)
-				} else if side == "right" {
-					pdf.Text(xPosition+benchWidth/2+5, yPosition+10, studentID)
-				}
-			}
+// StudentAssignments flattens the room plans returned for one student into
+// one response per seat.
+func StudentAssignments(examAssignments []models.ExamAssignment) []StudentAssignmentResponse {
+	responses := []StudentAssignmentResponse{}
+	for _, assignment := range examAssignments {
+		for _, seat := range assignment.Seats {
+			responses = append(responses, NewStudentAssignmentResponse(assignment, seat))
 		}
 	}
-
-	pdfFilePath := "assignments.pdf"
-	err := pdf.OutputFileAndClose(pdfFilePath)
-	if err != nil {
-		return "", err
-	}
-
-	return pdfFilePath, nil
+	return responses
 }
@@ -0,0 +1,120 @@
+package helpers
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"sync"
+	"time"
+)
+
+// KeySet resolves the public key that verifies an RS256 token from its kid.
+type KeySet interface {
+	PublicKey(kid string) (*rsa.PublicKey, error)
+}
+
+// JWKSFile is a KeySet read from a local JSON Web Key Set file. The file is
+// re-read when it changes, so keys can be rotated by rewriting it.
+type JWKSFile struct {
+	path string
+
+	mu      sync.RWMutex
+	modTime time.Time
+	keys    map[string]*rsa.PublicKey
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+func LoadJWKSFile(path string) (*JWKSFile, error) {
+	jwks := &JWKSFile{path: path}
+	if err := jwks.reload(); err != nil {
+		return nil, err
+	}
+	return jwks, nil
+}
+
+func (j *JWKSFile) PublicKey(kid string) (*rsa.PublicKey, error) {
+	if info, err := os.Stat(j.path); err == nil {
+		j.mu.RLock()
+		changed := !info.ModTime().Equal(j.modTime)
+		j.mu.RUnlock()
+		if changed {
+			if err := j.reload(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	key, found := j.keys[kid]
+	if !found {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+	return key, nil
+}
+
+func (j *JWKSFile) reload() error {
+	info, err := os.Stat(j.path)
+	if err != nil {
+		return fmt.Errorf("error reading JWKS file: %w", err)
+	}
+	data, err := os.ReadFile(j.path)
+	if err != nil {
+		return fmt.Errorf("error reading JWKS file: %w", err)
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return fmt.Errorf("error parsing JWKS file: %w", err)
+	}
+
+	keys := map[string]*rsa.PublicKey{}
+	for _, key := range set.Keys {
+		if key.Kty != "RSA" || (key.Use != "" && key.Use != "sig") || (key.Alg != "" && key.Alg != AlgorithmRS256) {
+			continue
+		}
+		publicKey, err := key.rsaPublicKey()
+		if err != nil {
+			return fmt.Errorf("error parsing JWKS key %q: %w", key.Kid, err)
+		}
+		keys[key.Kid] = publicKey
+	}
+	if len(keys) == 0 {
+		return fmt.Errorf("JWKS file %s has no RSA signing keys", j.path)
+	}
+
+	j.mu.Lock()
+	j.keys = keys
+	j.modTime = info.ModTime()
+	j.mu.Unlock()
+	return nil
+}
+
+func (k jsonWebKey) rsaPublicKey() (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil {
+		return nil, fmt.Errorf("invalid modulus: %w", err)
+	}
+	e, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil {
+		return nil, fmt.Errorf("invalid exponent: %w", err)
+	}
+	exponent := new(big.Int).SetBytes(e)
+	if !exponent.IsInt64() || exponent.Int64() < 3 || exponent.Int64() > 1<<31-1 {
+		return nil, fmt.Errorf("invalid exponent")
+	}
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+}
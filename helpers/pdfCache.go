@@ -0,0 +1,63 @@
+package helpers
+
+import (
+	"container/list"
+	"sync"
+)
+
+// PDFCache keeps the most recently used rendered PDFs by key. A nil cache
+// stores nothing.
+type PDFCache struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type pdfCacheEntry struct {
+	key  string
+	data []byte
+}
+
+// NewPDFCache returns a cache holding up to size PDFs, or nil when size is 0.
+func NewPDFCache(size int) *PDFCache {
+	if size <= 0 {
+		return nil
+	}
+	return &PDFCache{size: size, order: list.New(), entries: map[string]*list.Element{}}
+}
+
+func (c *PDFCache) Get(key string) ([]byte, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, found := c.entries[key]
+	if !found {
+		return nil, false
+	}
+	c.order.MoveToFront(element)
+	return element.Value.(*pdfCacheEntry).data, true
+}
+
+func (c *PDFCache) Put(key string, data []byte) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, found := c.entries[key]; found {
+		element.Value.(*pdfCacheEntry).data = data
+		c.order.MoveToFront(element)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&pdfCacheEntry{key: key, data: data})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*pdfCacheEntry).key)
+	}
+}
@@ -0,0 +1,136 @@
+package helpers
+
+import (
+	"DevMaan707/UMS/models"
+	"time"
+)
+
+// SeatingPlan is the seat map produced for one exam window.
+type SeatingPlan struct {
+	TOE   time.Time
+	DOE   time.Duration
+	Rooms []RoomPlan
+}
+
+// RoomPlan is one room of a seating plan. Its JSON keeps the room and
+// assignments keys the API has always returned.
+type RoomPlan struct {
+	ID          uint             `json:"id,omitempty"`
+	RoomID      uint             `json:"room_id,omitempty"`
+	Room        string           `json:"room"`
+	Block       string           `json:"block,omitempty"`
+	Floor       int              `json:"floor"`
+	Building    string           `json:"building,omitempty"`
+	Assignments []SeatAssignment `json:"assignments"`
+	Stats       models.RoomStats `json:"stats"`
+}
+
+type SeatAssignment struct {
+	StudentID string    `json:"student_id"`
+	Row       int       `json:"row"`
+	Column    int       `json:"column"`
+	Side      string    `json:"side"`
+	Subject   string    `json:"subject,omitempty"`
+	TOE       time.Time `json:"toe"`
+	DOE       string    `json:"doe"`
+}
+
+func NewSeatingPlan(toe time.Time, doe time.Duration) SeatingPlan {
+	return SeatingPlan{TOE: toe, DOE: doe, Rooms: []RoomPlan{}}
+}
+
+func newRoomPlan(room Room, seats []SeatAssignment) RoomPlan {
+	return RoomPlan{
+		RoomID:      room.ID,
+		Room:        room.RoomNumber,
+		Block:       room.Block,
+		Floor:       room.Floor,
+		Building:    room.Building,
+		Assignments: seats,
+	}
+}
+
+func (p SeatingPlan) seat(studentID string, row, column int, side, subject string) SeatAssignment {
+	return SeatAssignment{
+		StudentID: studentID,
+		Row:       row,
+		Column:    column,
+		Side:      side,
+		Subject:   subject,
+		TOE:       p.TOE,
+		DOE:       p.DOE.String(),
+	}
+}
+
+// Seated reports every student ID with a seat in the plan.
+func (p SeatingPlan) Seated() map[string]bool {
+	seated := map[string]bool{}
+	for _, room := range p.Rooms {
+		for _, seat := range room.Assignments {
+			seated[seat.StudentID] = true
+		}
+	}
+	return seated
+}
+
+func ToExamAssignments(plan SeatingPlan) []models.ExamAssignment {
+	examAssignments := make([]models.ExamAssignment, 0, len(plan.Rooms))
+	for _, room := range plan.Rooms {
+		seats := make([]models.SeatAssignment, 0, len(room.Assignments))
+		for _, seat := range room.Assignments {
+			seats = append(seats, models.SeatAssignment{
+				StudentID:  seat.StudentID,
+				RoomNumber: room.Room,
+				Row:        seat.Row,
+				Column:     seat.Column,
+				Side:       seat.Side,
+				Subject:    seat.Subject,
+				TOE:        plan.TOE.UTC(),
+				DOE:        plan.DOE.String(),
+			})
+		}
+
+		examAssignments = append(examAssignments, models.ExamAssignment{
+			RoomID:     int(room.RoomID),
+			RoomNumber: room.Room,
+			Block:      room.Block,
+			Floor:      room.Floor,
+			Building:   room.Building,
+			TOE:        plan.TOE.UTC(),
+			DOE:        plan.DOE.String(),
+			EndTime:    plan.TOE.Add(plan.DOE).UTC(),
+			Stats:      room.Stats,
+			Seats:      seats,
+		})
+	}
+	return examAssignments
+}
+
+func FromExamAssignments(examAssignments []models.ExamAssignment) []RoomPlan {
+	rooms := make([]RoomPlan, 0, len(examAssignments))
+	for _, examAssignment := range examAssignments {
+		seats := make([]SeatAssignment, 0, len(examAssignment.Seats))
+		for _, seat := range examAssignment.Seats {
+			seats = append(seats, SeatAssignment{
+				StudentID: seat.StudentID,
+				Row:       seat.Row,
+				Column:    seat.Column,
+				Side:      seat.Side,
+				Subject:   seat.Subject,
+				TOE:       seat.TOE,
+				DOE:       seat.DOE,
+			})
+		}
+		rooms = append(rooms, RoomPlan{
+			ID:          examAssignment.ID,
+			RoomID:      uint(examAssignment.RoomID),
+			Room:        examAssignment.RoomNumber,
+			Block:       assignmentBlock(examAssignment),
+			Floor:       examAssignment.Floor,
+			Building:    examAssignment.Building,
+			Assignments: seats,
+			Stats:       examAssignment.Stats,
+		})
+	}
+	return rooms
+}
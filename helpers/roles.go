@@ -0,0 +1,51 @@
+package helpers
+
+import "slices"
+
+const (
+	RoleAdmin       = "admin"
+	RoleExamCell    = "exam_cell"
+	RoleInvigilator = "invigilator"
+	RoleStudent     = "student"
+)
+
+const (
+	PermissionManageUsers  = "users:manage"
+	PermissionManageSetup  = "setup:manage"
+	PermissionManagePlans  = "plans:manage"
+	PermissionViewPlans    = "plans:view"
+	PermissionViewOwnRooms = "rooms:view_own"
+	PermissionViewAnySeat  = "seats:view_any"
+	PermissionViewOwnSeat  = "seats:view_own"
+	PermissionAssignDuty   = "invigilation:assign"
+	PermissionVerifyTicket = "hall_tickets:verify"
+)
+
+// rolePermissions lists what each role may do. Setup covers rooms, class
+// rosters and the exam timetable. Invigilators see seats only in the rooms
+// they are on duty in.
+var rolePermissions = map[string][]string{
+	RoleAdmin: {
+		PermissionManageUsers, PermissionManageSetup, PermissionManagePlans, PermissionViewPlans,
+		PermissionViewAnySeat, PermissionAssignDuty, PermissionVerifyTicket,
+	},
+	RoleExamCell: {
+		PermissionManageSetup, PermissionManagePlans, PermissionViewPlans,
+		PermissionViewAnySeat, PermissionAssignDuty, PermissionVerifyTicket,
+	},
+	RoleInvigilator: {PermissionViewOwnRooms, PermissionVerifyTicket},
+	RoleStudent:     {PermissionViewOwnSeat},
+}
+
+func ValidRole(role string) bool {
+	_, found := rolePermissions[role]
+	return found
+}
+
+func PermissionsFor(role string) []string {
+	return slices.Clone(rolePermissions[role])
+}
+
+func HasPermission(permissions []string, permission string) bool {
+	return slices.Contains(permissions, permission)
+}
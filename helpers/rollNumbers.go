@@ -0,0 +1,163 @@
+package helpers
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// RollNumber is what a student ID encodes, e.g. 23EG105D50 is admitted in
+// 2023 to college EG, branch 105, section D, serial 50.
+type RollNumber struct {
+	StudentID     string `json:"student_id"`
+	AdmissionYear int    `json:"admission_year"`
+	CollegeCode   string `json:"college_code"`
+	BranchCode    string `json:"branch_code"`
+	Section       string `json:"section"`
+	Serial        string `json:"serial"`
+}
+
+type RollNumberParser interface {
+	Parse(studentID string) (RollNumber, error)
+}
+
+// DefaultRollNumberPattern matches IDs such as 23EG105D50 and 23EG105DA0.
+const DefaultRollNumberPattern = `^(?P<year>\d{2})(?P<college>[A-Z]{2})(?P<branch>\d{3})(?P<section>[A-Z])(?P<serial>[0-9A-Z]\d)$`
+
+var rollNumberGroups = []string{"year", "college", "branch", "section", "serial"}
+
+type regexRollNumberParser struct {
+	pattern *regexp.Regexp
+}
+
+// NewRegexRollNumberParser builds a parser from a regular expression with the
+// named groups year, college, branch, section and serial. Only year is
+// required; a two-digit year is read as 20YY.
+func NewRegexRollNumberParser(pattern string) (RollNumberParser, error) {
+	compiled, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid roll number pattern: %w", err)
+	}
+	if compiled.SubexpIndex("year") < 0 {
+		return nil, fmt.Errorf("roll number pattern must have a year group")
+	}
+	return &regexRollNumberParser{pattern: compiled}, nil
+}
+
+// NewFormatRollNumberParser builds a parser from a fixed-width format where
+// each character stands for one character of the ID: Y year digit, C college
+// letter, B branch character, S section letter, N serial character. Any other
+// character must appear literally, e.g. "YYCCBBBSNN".
+func NewFormatRollNumberParser(format string) (RollNumberParser, error) {
+	classes := map[rune]struct{ group, class string }{
+		'Y': {"year", `\d`},
+		'C': {"college", `[A-Z]`},
+		'B': {"branch", `[0-9A-Z]`},
+		'S': {"section", `[A-Z]`},
+		'N': {"serial", `[0-9A-Z]`},
+	}
+
+	var pattern strings.Builder
+	pattern.WriteString("^")
+	seen := map[rune]bool{}
+	runes := []rune(format)
+	for i := 0; i < len(runes); {
+		spec, found := classes[runes[i]]
+		if !found {
+			pattern.WriteString(regexp.QuoteMeta(string(runes[i])))
+			i++
+			continue
+		}
+		if seen[runes[i]] {
+			return nil, fmt.Errorf("roll number format %q repeats %c outside one run", format, runes[i])
+		}
+		seen[runes[i]] = true
+
+		width := 1
+		for i+width < len(runes) && runes[i+width] == runes[i] {
+			width++
+		}
+		fmt.Fprintf(&pattern, "(?P<%s>%s{%d})", spec.group, spec.class, width)
+		i += width
+	}
+	pattern.WriteString("$")
+
+	return NewRegexRollNumberParser(pattern.String())
+}
+
+func (p *regexRollNumberParser) Parse(studentID string) (RollNumber, error) {
+	studentID = NormalizeStudentID(studentID)
+	match := p.pattern.FindStringSubmatch(studentID)
+	if match == nil {
+		return RollNumber{}, fmt.Errorf("student id %q does not match the roll number format", studentID)
+	}
+
+	fields := map[string]string{}
+	for _, group := range rollNumberGroups {
+		if index := p.pattern.SubexpIndex(group); index >= 0 {
+			fields[group] = match[index]
+		}
+	}
+
+	year, err := strconv.Atoi(fields["year"])
+	if err != nil {
+		return RollNumber{}, fmt.Errorf("student id %q has an invalid admission year", studentID)
+	}
+	if year < 100 {
+		year += 2000
+	}
+
+	return RollNumber{
+		StudentID:     studentID,
+		AdmissionYear: year,
+		CollegeCode:   fields["college"],
+		BranchCode:    fields["branch"],
+		Section:       fields["section"],
+		Serial:        fields["serial"],
+	}, nil
+}
+
+var rollNumberParser RollNumberParser
+
+func init() {
+	parser, err := NewRegexRollNumberParser(DefaultRollNumberPattern)
+	if err != nil {
+		panic(err)
+	}
+	rollNumberParser = parser
+}
+
+func SetRollNumberParser(parser RollNumberParser) {
+	rollNumberParser = parser
+}
+
+func ParseRollNumber(studentID string) (RollNumber, error) {
+	return rollNumberParser.Parse(studentID)
+}
+
+// InvalidStudentIDsError lists the IDs in a roster that the roll number
+// parser rejected.
+type InvalidStudentIDsError struct {
+	ClassName  string
+	StudentIDs []string
+}
+
+func (e *InvalidStudentIDsError) Error() string {
+	return fmt.Sprintf("class %s has malformed student ids: %s", e.ClassName, strings.Join(e.StudentIDs, ", "))
+}
+
+// ValidateStudentIDs returns an InvalidStudentIDsError naming every ID that
+// does not parse.
+func ValidateStudentIDs(className string, studentIDs []string) error {
+	invalid := []string{}
+	for _, studentID := range studentIDs {
+		if _, err := ParseRollNumber(studentID); err != nil {
+			invalid = append(invalid, studentID)
+		}
+	}
+	if len(invalid) > 0 {
+		return &InvalidStudentIDsError{ClassName: className, StudentIDs: invalid}
+	}
+	return nil
+}
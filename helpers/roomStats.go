@@ -0,0 +1,71 @@
+package helpers
+
+import (
+	"DevMaan707/UMS/models"
+)
+
+// BuildRoomStats tallies the seats of one room against the roster. Students
+// missing from the roster still count towards the headcount and ranges.
+func BuildRoomStats(room Room, params models.Params, seats []SeatAssignment, roster map[string]RosterEntry) models.RoomStats {
+	stats := models.RoomStats{
+		Capacity:         RoomCapacity(room, params),
+		Branches:         map[string]int{},
+		Years:            map[int]int{},
+		Sections:         map[string]int{},
+		RollNumberRanges: []models.RollNumberRange{},
+	}
+
+	studentIDs := make([]string, 0, len(seats))
+	for _, seat := range seats {
+		studentID := seat.StudentID
+		stats.Headcount++
+		studentIDs = append(studentIDs, studentID)
+
+		entry, found := roster[studentID]
+		if !found {
+			continue
+		}
+		stats.Branches[entry.Branch]++
+		stats.Years[entry.Year]++
+		section := entry.Section
+		if section == "" {
+			roll, _ := ParseRollNumber(studentID)
+			section = roll.Section
+		}
+		if section != "" {
+			stats.Sections[section]++
+		}
+	}
+
+	stats.SeatsUsed = stats.Headcount
+	stats.SeatsFree = max(stats.Capacity-stats.SeatsUsed, 0)
+
+	// One range per run of consecutive roll numbers, so a gap in a prefix
+	// starts a new range rather than being hidden inside one.
+	for _, run := range rollNumberRuns(studentIDs) {
+		stats.RollNumberRanges = append(stats.RollNumberRanges, models.RollNumberRange{
+			Prefix: run.first.prefix,
+			From:   run.first.studentID,
+			To:     run.last.studentID,
+			Count:  run.count,
+		})
+	}
+
+	return stats
+}
+
+// AttachRoomStats fills in the stats of every room of a seating plan.
+func AttachRoomStats(plan SeatingPlan, rooms []Room, params models.Params, roster []RosterEntry) {
+	byID := map[uint]Room{}
+	for _, room := range rooms {
+		byID[room.ID] = room
+	}
+	entries := make(map[string]RosterEntry, len(roster))
+	for _, entry := range roster {
+		entries[entry.StudentID] = entry
+	}
+
+	for i, room := range plan.Rooms {
+		plan.Rooms[i].Stats = BuildRoomStats(byID[room.RoomID], params, room.Assignments, entries)
+	}
+}
@@ -0,0 +1,161 @@
+package helpers
+
+import (
+	"DevMaan707/UMS/models"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type Room struct {
+	ID            uint    `json:"id"`
+	Block         string  `json:"block"`
+	Floor         int     `json:"floor"`
+	Building      string  `json:"building"`
+	Door          string  `json:"door,omitempty"`
+	RoomType      string  `json:"room_type"`
+	Capacity      int     `json:"capacity"`
+	RoomNumber    string  `json:"room_number"`
+	AssignedClass string  `json:"assigned_class"`
+	Rows          int     `json:"rows"`
+	Columns       int     `json:"columns"`
+	SeatsPerBench int     `json:"seats_per_bench"`
+	Spacing       float64 `json:"spacing"`
+}
+
+const (
+	RoomTypeClassroom   = "classroom"
+	RoomTypeLab         = "lab"
+	RoomTypeSeminarHall = "seminar_hall"
+	RoomTypeDrawingHall = "drawing_hall"
+)
+
+// Door positions, seen from the back of the room facing the blackboard.
+const (
+	DoorFrontLeft  = "front-left"
+	DoorFrontRight = "front-right"
+	DoorBackLeft   = "back-left"
+	DoorBackRight  = "back-right"
+)
+
+func validDoor(door string) bool {
+	return door == DoorFrontLeft || door == DoorFrontRight || door == DoorBackLeft || door == DoorBackRight
+}
+
+// RoomLayout holds the defaults for a room type. Spacing scales the gap
+// between benches relative to a classroom when the room is drawn.
+type RoomLayout struct {
+	SeatsPerBench int
+	Spacing       float64
+}
+
+var roomLayouts = map[string]RoomLayout{
+	RoomTypeClassroom:   {SeatsPerBench: 2, Spacing: 1},
+	RoomTypeLab:         {SeatsPerBench: 1, Spacing: 1},
+	RoomTypeSeminarHall: {SeatsPerBench: 3, Spacing: 1},
+	RoomTypeDrawingHall: {SeatsPerBench: 1, Spacing: 2},
+}
+
+func NormalizeRoomType(roomType string) string {
+	roomType = strings.ToLower(strings.TrimSpace(roomType))
+	return strings.NewReplacer(" ", "_", "-", "_").Replace(roomType)
+}
+
+func LayoutFor(roomType string) (RoomLayout, bool) {
+	layout, found := roomLayouts[NormalizeRoomType(roomType)]
+	return layout, found
+}
+
+// BlockFromRoomNumber reads the block prefix of room numbers such as "A-01".
+func BlockFromRoomNumber(roomNumber string) string {
+	block, _, found := strings.Cut(strings.TrimSpace(roomNumber), "-")
+	if !found {
+		return ""
+	}
+	return strings.TrimSpace(block)
+}
+
+// RoomLocation describes where a room is, e.g. "Block A, Floor 1, Main Building".
+func RoomLocation(block string, floor int, building string) string {
+	parts := []string{}
+	if block != "" {
+		parts = append(parts, "Block "+block)
+	}
+	parts = append(parts, "Floor "+strconv.Itoa(floor))
+	if building != "" {
+		parts = append(parts, building)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func NormalizeRoom(room *models.Room) error {
+	room.Block = strings.TrimSpace(room.Block)
+	room.RoomNumber = strings.TrimSpace(room.RoomNumber)
+	room.RoomType = NormalizeRoomType(room.RoomType)
+	room.Building = strings.TrimSpace(room.Building)
+	room.Door = strings.NewReplacer(" ", "-", "_", "-").Replace(strings.ToLower(strings.TrimSpace(room.Door)))
+
+	if room.Block == "" {
+		room.Block = BlockFromRoomNumber(room.RoomNumber)
+	}
+	if room.Block == "" {
+		return fmt.Errorf("block is required")
+	}
+	if room.RoomNumber == "" {
+		return fmt.Errorf("room_number is required")
+	}
+	if room.Rows <= 0 || room.Columns <= 0 {
+		return fmt.Errorf("rows and columns must be positive for room %s", room.RoomNumber)
+	}
+	if room.Door != "" && !validDoor(room.Door) {
+		return fmt.Errorf("unknown door %q for room %s", room.Door, room.RoomNumber)
+	}
+	if room.RoomType == "" {
+		room.RoomType = RoomTypeClassroom
+	}
+	layout, found := roomLayouts[room.RoomType]
+	if !found {
+		return fmt.Errorf("unknown room_type %q for room %s", room.RoomType, room.RoomNumber)
+	}
+	if room.SeatsPerBench <= 0 {
+		room.SeatsPerBench = layout.SeatsPerBench
+	}
+	if room.Spacing <= 0 {
+		room.Spacing = layout.Spacing
+	}
+	if room.Capacity <= 0 {
+		room.Capacity = room.Rows * room.Columns * room.SeatsPerBench
+	}
+	return nil
+}
+
+func NewRoom(room models.Room) Room {
+	layout, found := LayoutFor(room.RoomType)
+	if !found {
+		layout = roomLayouts[RoomTypeClassroom]
+	}
+	seatsPerBench := room.SeatsPerBench
+	if seatsPerBench <= 0 {
+		seatsPerBench = layout.SeatsPerBench
+	}
+	spacing := room.Spacing
+	if spacing <= 0 {
+		spacing = layout.Spacing
+	}
+
+	return Room{
+		ID:            room.ID,
+		Block:         room.Block,
+		Floor:         room.Floor,
+		Building:      room.Building,
+		Door:          room.Door,
+		RoomType:      room.RoomType,
+		Capacity:      room.Capacity,
+		RoomNumber:    room.RoomNumber,
+		AssignedClass: room.ClassAssigned,
+		Rows:          room.Rows,
+		Columns:       room.Columns,
+		SeatsPerBench: seatsPerBench,
+		Spacing:       spacing,
+	}
+}
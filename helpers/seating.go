@@ -0,0 +1,182 @@
+package helpers
+
+import (
+	"DevMaan707/UMS/models"
+	"fmt"
+	"time"
+)
+
+const (
+	SingleChildAlternate = "alternate"
+	SingleChildCenter    = "center"
+)
+
+const (
+	PatternCheckerboard = "checkerboard"
+	PatternStripes      = "stripes"
+	PatternDiagonal     = "diagonal"
+)
+
+func ValidPattern(pattern string) bool {
+	return pattern == PatternCheckerboard || pattern == PatternStripes || pattern == PatternDiagonal
+}
+
+// RoomCapacity is the number of students a room seats under params: one per
+// bench in SingleChild mode, otherwise every seat on the bench, capped at the
+// room's stored capacity when one is set.
+func RoomCapacity(room Room, params models.Params) int {
+	seats := room.Rows * room.Columns * seatsUsedPerBench(room, params)
+	if room.Capacity > 0 {
+		return min(seats, room.Capacity)
+	}
+	return seats
+}
+
+func seatsUsedPerBench(room Room, params models.Params) int {
+	if params.SingleChild || room.SeatsPerBench <= 1 {
+		return 1
+	}
+	return room.SeatsPerBench
+}
+
+// seatSide names a seat on its bench. Single-seat stations are "center";
+// benches with three or more seats number the inner ones.
+func seatSide(room Room, params models.Params, row, column, seat int) string {
+	if room.SeatsPerBench <= 1 {
+		return "center"
+	}
+	if params.SingleChild {
+		return singleChildSide(row, column, params.SingleChildPosition)
+	}
+	switch {
+	case seat == 0:
+		return "left"
+	case seat == room.SeatsPerBench-1:
+		return "right"
+	case room.SeatsPerBench == 3:
+		return "middle"
+	}
+	return fmt.Sprintf("seat-%d", seat+1)
+}
+
+func benchPosition(room Room, benchIndex int, rowWise bool) (int, int) {
+	if rowWise {
+		return (benchIndex / room.Columns) + 1, (benchIndex % room.Columns) + 1
+	}
+	return (benchIndex % room.Rows) + 1, (benchIndex / room.Rows) + 1
+}
+
+// singleChildSide places the lone student of a bench. Alternating sides
+// follow a checkerboard so neither the bench in front nor the one beside
+// has a student directly in line.
+func singleChildSide(row, column int, position string) string {
+	if position == SingleChildCenter {
+		return "center"
+	}
+	if (row+column)%2 == 0 {
+		return "left"
+	}
+	return "right"
+}
+
+// patternSlot picks which of the room's branches sits in a seat. row and
+// seatColumn are zero-based positions on the grid of seats, so neighbours on
+// a bench are one seatColumn apart. Every pattern gives left/right and
+// front/back neighbours different branches whenever branches > 1:
+//
+//	checkerboard  branches cycle along both rows and columns
+//	stripes       each seat column holds one branch, shifted on alternate rows
+//	diagonal      branches step by two per row, which also keeps diagonal
+//	              neighbours apart once four or more branches share the room
+func patternSlot(pattern string, row, seatColumn, branches int) int {
+	switch pattern {
+	case PatternStripes:
+		return (seatColumn + row%2) % branches
+	case PatternDiagonal:
+		if branches > 2 {
+			return (seatColumn + 2*row) % branches
+		}
+	}
+	return (row + seatColumn) % branches
+}
+
+func GenerateExamAssignments(assignType string, rooms []Room, students map[string][]string, params models.Params, toe time.Time, doe time.Duration) SeatingPlan {
+	plan := NewSeatingPlan(toe, doe)
+
+	branchesPerRoom := params.NumberOfBranchesInRoom
+	if branchesPerRoom < 1 {
+		branchesPerRoom = 1
+	}
+	pickBranches := func(count int, exclude map[string]bool) []string {
+		picked := []string{}
+		for _, branch := range params.Branches {
+			if len(picked) == count {
+				break
+			}
+			if len(students[branch]) > 0 && !exclude[branch] {
+				picked = append(picked, branch)
+			}
+		}
+		return picked
+	}
+
+	for _, room := range rooms {
+		roomBranches := pickBranches(branchesPerRoom, nil)
+		if len(roomBranches) == 0 {
+			break
+		}
+		inRoom := map[string]bool{}
+		for _, branch := range roomBranches {
+			inRoom[branch] = true
+		}
+		// With fewer branches left than the room mixes, the missing branches'
+		// seats stay empty rather than seating one branch side by side. Whoever
+		// does not fit moves on to the next room or is reported unseated.
+		for len(roomBranches) < branchesPerRoom {
+			roomBranches = append(roomBranches, "")
+		}
+
+		totalBenches := room.Rows * room.Columns
+		seatsPerBench := seatsUsedPerBench(room, params)
+		capacity := RoomCapacity(room, params)
+		assignedStudents := []SeatAssignment{}
+
+		seatStudent := func(branch string, row, column int, side string) {
+			studentID := students[branch][0]
+			students[branch] = students[branch][1:]
+
+			assignedStudents = append(assignedStudents, plan.seat(studentID, row, column, side, ""))
+		}
+
+		for benchIndex := 0; benchIndex < totalBenches; benchIndex++ {
+			row, column := benchPosition(room, benchIndex, params.RowWise)
+
+			for seat := 0; seat < seatsPerBench && len(assignedStudents) < capacity; seat++ {
+				slot := patternSlot(params.Pattern, row-1, (column-1)*seatsPerBench+seat, len(roomBranches))
+				branch := roomBranches[slot]
+
+				// A branch that runs out mid-room hands its seats to a branch
+				// not yet in the room, so the pattern still holds. With none
+				// left those seats stay empty.
+				if len(students[branch]) == 0 {
+					if branchesPerRoom == 1 {
+						continue
+					}
+					fresh := pickBranches(1, inRoom)
+					if len(fresh) == 0 {
+						continue
+					}
+					branch = fresh[0]
+					roomBranches[slot] = branch
+					inRoom[branch] = true
+				}
+
+				seatStudent(branch, row, column, seatSide(room, params, row, column, seat))
+			}
+		}
+
+		plan.Rooms = append(plan.Rooms, newRoomPlan(room, assignedStudents))
+	}
+
+	return plan
+}
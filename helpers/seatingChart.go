@@ -0,0 +1,405 @@
+package helpers
+
+import (
+	"DevMaan707/UMS/models"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// Sizes of the seating chart in mm. Seats shrink towards the minimum before a
+// room is spread over more pages.
+const (
+	chartMargin     = 10.0
+	chartGutter     = 8.0
+	chartHeader     = 26.0
+	chartBoard      = 14.0
+	chartLegend     = 16.0
+	minSeatWidth    = 17.0
+	maxSeatWidth    = 30.0
+	minSeatHeight   = 9.0
+	maxSeatHeight   = 16.0
+	chartAisle      = 4.0
+	chartRowSpacing = 2.5
+)
+
+var branchPalette = [][3]int{
+	{166, 206, 227}, {178, 223, 138}, {251, 154, 153}, {253, 191, 111}, {202, 178, 214},
+	{255, 255, 153}, {141, 211, 199}, {252, 205, 229}, {217, 217, 217}, {204, 235, 197},
+}
+
+// roomGeometry is the bench grid a room plan is drawn on.
+type roomGeometry struct {
+	Rows          int
+	Columns       int
+	SeatsPerBench int
+	Spacing       float64
+	Door          string
+}
+
+// chartGeometry takes the grid from the room when it is known and grows it
+// to fit every stored seat, so plans outlive later edits to the room.
+func chartGeometry(assignment models.ExamAssignment, room Room, found bool) roomGeometry {
+	geometry := roomGeometry{SeatsPerBench: 1, Spacing: 1}
+	if found {
+		geometry = roomGeometry{
+			Rows:          room.Rows,
+			Columns:       room.Columns,
+			SeatsPerBench: max(room.SeatsPerBench, 1),
+			Spacing:       room.Spacing,
+			Door:          room.Door,
+		}
+	}
+	for _, seat := range assignment.Seats {
+		geometry.Rows = max(geometry.Rows, seat.Row)
+		geometry.Columns = max(geometry.Columns, seat.Column)
+		if found {
+			continue
+		}
+		switch seat.Side {
+		case "middle":
+			geometry.SeatsPerBench = max(geometry.SeatsPerBench, 3)
+		case "left", "right":
+			geometry.SeatsPerBench = max(geometry.SeatsPerBench, 2)
+		}
+	}
+	if geometry.Spacing <= 0 {
+		geometry.Spacing = 1
+	}
+	return geometry
+}
+
+// chartLayout is how a room is split over pages and how big its seats are.
+type chartLayout struct {
+	Orientation  string
+	PageWidth    float64
+	PageHeight   float64
+	SeatWidth    float64
+	SeatHeight   float64
+	RowsPerPage  int
+	BenchPerPage int
+}
+
+func (l chartLayout) pages(geometry roomGeometry) int {
+	return ceilDiv(geometry.Rows, l.RowsPerPage) * ceilDiv(geometry.Columns, l.BenchPerPage)
+}
+
+func ceilDiv(a, b int) int {
+	return (a + b - 1) / b
+}
+
+// fitChart finds how many items of a minimum size, separated by gap, fit in
+// available space, and the size each gets when only that many are drawn.
+func fitChart(available, gap, minimum, maximum float64, count, itemsPer int) (int, float64) {
+	fit := count
+	for fit > 1 && (available-float64(fit-1)*gap)/float64(fit*itemsPer) < minimum {
+		fit--
+	}
+	size := (available - float64(fit-1)*gap) / float64(fit*itemsPer)
+	return fit, min(size, maximum)
+}
+
+// planChart picks portrait or landscape A4, whichever needs fewer pages.
+func planChart(geometry roomGeometry) chartLayout {
+	var best chartLayout
+	for _, orientation := range []string{"P", "L"} {
+		width, height := 210.0, 297.0
+		if orientation == "L" {
+			width, height = height, width
+		}
+		aisle := chartAisle * geometry.Spacing
+		rowSpacing := chartRowSpacing * geometry.Spacing
+		availableWidth := width - 2*chartMargin - chartGutter
+		availableHeight := height - 2*chartMargin - chartHeader - chartBoard - chartLegend
+
+		layout := chartLayout{Orientation: orientation, PageWidth: width, PageHeight: height}
+		layout.BenchPerPage, layout.SeatWidth = fitChart(availableWidth, aisle, minSeatWidth, maxSeatWidth, geometry.Columns, geometry.SeatsPerBench)
+		layout.RowsPerPage, layout.SeatHeight = fitChart(availableHeight, rowSpacing, minSeatHeight, maxSeatHeight, geometry.Rows, 1)
+		if best.Orientation == "" || layout.pages(geometry) < best.pages(geometry) {
+			best = layout
+		}
+	}
+	return best
+}
+
+// benchSlots names the seats of a bench from left to right.
+func benchSlots(seatsPerBench int) []string {
+	room := Room{SeatsPerBench: seatsPerBench}
+	slots := make([]string, 0, seatsPerBench)
+	for seat := 0; seat < max(seatsPerBench, 1); seat++ {
+		slots = append(slots, seatSide(room, models.Params{}, 0, 0, seat))
+	}
+	return slots
+}
+
+// seatBranch is the branch a seat is coloured by: the roster's when known,
+// otherwise the branch code in the roll number.
+func seatBranch(studentID string, roster map[string]RosterEntry) string {
+	if entry, found := roster[studentID]; found && entry.Branch != "" {
+		return entry.Branch
+	}
+	if roll, err := ParseRollNumber(studentID); err == nil && roll.BranchCode != "" {
+		return roll.BranchCode
+	}
+	return "Other"
+}
+
+// GeneratePDF draws the seat map of every room plan to scale with the room's
+// benches, the blackboard at the top of the page and the door where the room
+// has one. Seats are coloured by branch with a legend, and empty seats are
+// crossed out. Rooms too big for one A4 page continue over several.
+func GeneratePDF(w io.Writer, assignments []models.ExamAssignment, rooms map[uint]Room, roster map[string]RosterEntry) error {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(chartMargin, chartMargin, chartMargin)
+	pdf.SetAutoPageBreak(false, 0)
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	branchSet := map[string]bool{}
+	for _, assignment := range assignments {
+		for _, seat := range assignment.Seats {
+			branchSet[seatBranch(seat.StudentID, roster)] = true
+		}
+	}
+	branches := make([]string, 0, len(branchSet))
+	for branch := range branchSet {
+		branches = append(branches, branch)
+	}
+	sort.Strings(branches)
+	colours := make(map[string][3]int, len(branches))
+	for i, branch := range branches {
+		colours[branch] = branchPalette[i%len(branchPalette)]
+	}
+
+	for _, assignment := range assignments {
+		room, found := rooms[uint(assignment.RoomID)]
+		geometry := chartGeometry(assignment, room, found)
+		if geometry.Rows == 0 || geometry.Columns == 0 {
+			continue
+		}
+		layout := planChart(geometry)
+		chart := seatingChart{pdf: pdf, tr: tr, assignment: assignment, geometry: geometry, layout: layout, roster: roster, colours: colours}
+
+		pages := layout.pages(geometry)
+		page := 0
+		for firstRow := 1; firstRow <= geometry.Rows; firstRow += layout.RowsPerPage {
+			for firstColumn := 1; firstColumn <= geometry.Columns; firstColumn += layout.BenchPerPage {
+				page++
+				lastRow := min(firstRow+layout.RowsPerPage-1, geometry.Rows)
+				lastColumn := min(firstColumn+layout.BenchPerPage-1, geometry.Columns)
+				chart.drawPage(page, pages, firstRow, lastRow, firstColumn, lastColumn)
+			}
+		}
+	}
+
+	return pdf.Output(w)
+}
+
+type seatingChart struct {
+	pdf        *gofpdf.Fpdf
+	tr         func(string) string
+	assignment models.ExamAssignment
+	geometry   roomGeometry
+	layout     chartLayout
+	roster     map[string]RosterEntry
+	colours    map[string][3]int
+}
+
+func (s seatingChart) drawPage(page, pages, firstRow, lastRow, firstColumn, lastColumn int) {
+	pdf, geometry, layout := s.pdf, s.geometry, s.layout
+	pdf.AddPageFormat(layout.Orientation, gofpdf.SizeType{Wd: 210, Ht: 297})
+
+	title := "Room: " + s.assignment.RoomNumber
+	if pages > 1 {
+		title += fmt.Sprintf(" (page %d of %d: rows %d-%d, benches %d-%d)", page, pages, firstRow, lastRow, firstColumn, lastColumn)
+	}
+	pdf.SetFont("Arial", "B", 16)
+	pdf.CellFormat(0, 10, title, "", 1, "C", false, 0, "")
+	pdf.SetFont("Arial", "", 11)
+	pdf.CellFormat(0, 6, RoomLocation(assignmentBlock(s.assignment), s.assignment.Floor, s.assignment.Building), "", 1, "C", false, 0, "")
+	pdf.CellFormat(0, 6, examWindow(s.assignment.TOE, s.assignment.EndTime), "", 1, "C", false, 0, "")
+
+	aisle := chartAisle * geometry.Spacing
+	rowSpacing := chartRowSpacing * geometry.Spacing
+	benchWidth := layout.SeatWidth * float64(geometry.SeatsPerBench)
+	benches := lastColumn - firstColumn + 1
+	gridWidth := float64(benches)*benchWidth + float64(benches-1)*aisle
+	left := chartMargin + chartGutter + (layout.PageWidth-2*chartMargin-chartGutter-gridWidth)/2
+	boardTop := chartMargin + chartHeader
+	top := boardTop + chartBoard
+
+	// The blackboard is only drawn above the front row; later pages point
+	// towards it instead.
+	if firstRow == 1 {
+		pdf.SetFillColor(40, 70, 50)
+		pdf.Rect(left+gridWidth/4, boardTop, gridWidth/2, 7, "F")
+		pdf.SetTextColor(255, 255, 255)
+		pdf.SetFont("Arial", "B", 10)
+		pdf.SetXY(left+gridWidth/4, boardTop)
+		pdf.CellFormat(gridWidth/2, 7, "BLACKBOARD", "", 0, "C", false, 0, "")
+		pdf.SetTextColor(0, 0, 0)
+	} else {
+		pdf.SetFont("Arial", "I", 9)
+		pdf.SetXY(left, boardTop)
+		pdf.CellFormat(gridWidth, 7, "Blackboard is ahead of row 1", "", 0, "C", false, 0, "")
+	}
+
+	lastGridRow := top + float64(lastRow-firstRow+1)*(layout.SeatHeight+rowSpacing) - rowSpacing
+	s.drawDoor(firstRow, lastRow, firstColumn, lastColumn, left, gridWidth, boardTop, lastGridRow)
+
+	seats := map[[2]int][]models.SeatAssignment{}
+	for _, seat := range s.assignment.Seats {
+		bench := [2]int{seat.Row, seat.Column}
+		seats[bench] = append(seats[bench], seat)
+	}
+
+	slots := benchSlots(geometry.SeatsPerBench)
+	counts := map[string]int{}
+	empty := 0
+	pdf.SetDrawColor(0, 0, 0)
+	for row := firstRow; row <= lastRow; row++ {
+		y := top + float64(row-firstRow)*(layout.SeatHeight+rowSpacing)
+		pdf.SetFont("Arial", "", 8)
+		pdf.SetTextColor(110, 110, 110)
+		pdf.Text(left-chartGutter, y+layout.SeatHeight/2+1, fmt.Sprintf("R%d", row))
+		pdf.SetTextColor(0, 0, 0)
+
+		for column := firstColumn; column <= lastColumn; column++ {
+			x := left + float64(column-firstColumn)*(benchWidth+aisle)
+			bySide := map[string]models.SeatAssignment{}
+			for _, seat := range seats[[2]int{row, column}] {
+				bySide[seat.Side] = seat
+			}
+
+			// A lone student placed in the centre of a shared bench takes
+			// the whole bench.
+			if seat, centred := bySide["center"]; centred && len(slots) > 1 {
+				counts[s.drawSeat(seat, x, y, benchWidth)]++
+			} else {
+				for i, side := range slots {
+					seatX := x + float64(i)*layout.SeatWidth
+					if seat, taken := bySide[side]; taken {
+						counts[s.drawSeat(seat, seatX, y, layout.SeatWidth)]++
+						continue
+					}
+					s.drawEmptySeat(seatX, y, layout.SeatWidth)
+					empty++
+				}
+			}
+			pdf.SetLineWidth(0.6)
+			pdf.Rect(x, y, benchWidth, layout.SeatHeight, "D")
+			pdf.SetLineWidth(0.2)
+		}
+	}
+
+	s.drawLegend(counts, empty, lastGridRow+9)
+}
+
+// drawDoor marks the door on the side of the room it opens on, when that
+// side is on this page.
+func (s seatingChart) drawDoor(firstRow, lastRow, firstColumn, lastColumn int, left, gridWidth, boardTop, gridBottom float64) {
+	var y float64
+	switch s.geometry.Door {
+	case DoorFrontLeft, DoorFrontRight:
+		if firstRow != 1 {
+			return
+		}
+		y = boardTop
+	case DoorBackLeft, DoorBackRight:
+		if lastRow != s.geometry.Rows {
+			return
+		}
+		y = gridBottom + 1
+	default:
+		return
+	}
+
+	const doorWidth, doorHeight = 16.0, 5.0
+	x := left - chartGutter
+	switch s.geometry.Door {
+	case DoorFrontLeft, DoorBackLeft:
+		if firstColumn != 1 {
+			return
+		}
+	default:
+		if lastColumn != s.geometry.Columns {
+			return
+		}
+		x = left + gridWidth + chartGutter - doorWidth
+	}
+
+	pdf := s.pdf
+	pdf.SetFillColor(205, 133, 63)
+	pdf.Rect(x, y, doorWidth, doorHeight, "F")
+	pdf.SetFont("Arial", "B", 8)
+	pdf.SetXY(x, y)
+	pdf.CellFormat(doorWidth, doorHeight, "DOOR", "", 0, "C", false, 0, "")
+}
+
+// drawSeat fills a seat with its branch colour and student ID, and returns
+// the branch.
+func (s seatingChart) drawSeat(seat models.SeatAssignment, x, y, width float64) string {
+	pdf := s.pdf
+	branch := seatBranch(seat.StudentID, s.roster)
+	colour := s.colours[branch]
+	pdf.SetFillColor(colour[0], colour[1], colour[2])
+	pdf.Rect(x, y, width, s.layout.SeatHeight, "FD")
+
+	size := 10.0
+	pdf.SetFont("Arial", "B", size)
+	for size > 5 && pdf.GetStringWidth(seat.StudentID) > width-2 {
+		size -= 0.5
+		pdf.SetFontSize(size)
+	}
+	pdf.Text(x+(width-pdf.GetStringWidth(seat.StudentID))/2, y+s.layout.SeatHeight/2+size*0.35/2, seat.StudentID)
+	return branch
+}
+
+func (s seatingChart) drawEmptySeat(x, y, width float64) {
+	pdf := s.pdf
+	height := s.layout.SeatHeight
+	pdf.Rect(x, y, width, height, "D")
+	pdf.SetDrawColor(190, 190, 190)
+	pdf.Line(x+1, y+1, x+width-1, y+height-1)
+	pdf.Line(x+1, y+height-1, x+width-1, y+1)
+	pdf.SetDrawColor(0, 0, 0)
+}
+
+func (s seatingChart) drawLegend(counts map[string]int, empty int, y float64) {
+	pdf := s.pdf
+	branches := make([]string, 0, len(counts))
+	for branch := range counts {
+		branches = append(branches, branch)
+	}
+	sort.Strings(branches)
+
+	x := chartMargin
+	pdf.SetFont("Arial", "", 9)
+	swatch := func(label string, draw func(x float64)) {
+		width := 7 + pdf.GetStringWidth(label) + 6
+		if x+width > s.layout.PageWidth-chartMargin {
+			x = chartMargin
+			y += 6
+		}
+		draw(x)
+		pdf.Text(x+7, y+3.5, s.tr(label))
+		x += width
+	}
+	for _, branch := range branches {
+		colour := s.colours[branch]
+		swatch(fmt.Sprintf("%s (%d)", branch, counts[branch]), func(x float64) {
+			pdf.SetFillColor(colour[0], colour[1], colour[2])
+			pdf.Rect(x, y, 5, 4, "FD")
+		})
+	}
+	if empty > 0 {
+		swatch(fmt.Sprintf("Empty (%d)", empty), func(x float64) {
+			pdf.Rect(x, y, 5, 4, "D")
+			pdf.SetDrawColor(190, 190, 190)
+			pdf.Line(x+0.5, y+0.5, x+4.5, y+3.5)
+			pdf.Line(x+0.5, y+3.5, x+4.5, y+0.5)
+			pdf.SetDrawColor(0, 0, 0)
+		})
+	}
+}
@@ -0,0 +1,211 @@
+package helpers
+
+import (
+	"DevMaan707/UMS/models"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// InfeasibleError explains why the rooms supplied cannot seat every student
+// without two neighbours writing the same paper. SolvePaperSeating returns it
+// alongside the partial plan of the students it did seat.
+type InfeasibleError struct {
+	Reasons  []string       `json:"reasons"`
+	Unseated map[string]int `json:"unseated"`
+}
+
+func (e *InfeasibleError) Error() string {
+	return "seating infeasible: " + strings.Join(e.Reasons, "; ")
+}
+
+// LookupPaper finds a student's paper in papers keyed by "BRANCH:YEAR" or
+// "BRANCH".
+func LookupPaper(papers map[string]string, branch string, year int) (string, bool) {
+	if paper, found := papers[paperKey(branch, year)]; found {
+		return paper, true
+	}
+	paper, found := papers[branch]
+	return paper, found
+}
+
+// PaperFor is LookupPaper with students that have no entry writing a paper
+// named after their branch.
+func PaperFor(papers map[string]string, branch string, year int) string {
+	if paper, found := LookupPaper(papers, branch, year); found {
+		return paper
+	}
+	return branch
+}
+
+type seatSlot struct {
+	benchIndex int
+	seat       int
+	row        int
+	column     int
+	seatColumn int
+}
+
+// paperBins splits a room's seats into the four colour classes of the king
+// graph on its seat grid. No two seats in the same bin touch, horizontally,
+// vertically or diagonally, so filling bin by bin keeps each paper on
+// non-adjacent seats for as long as possible.
+func paperBins(room Room, params models.Params) [][]seatSlot {
+	seatsPerBench := seatsUsedPerBench(room, params)
+	bins := make([][]seatSlot, 4)
+	for benchIndex := 0; benchIndex < room.Rows*room.Columns; benchIndex++ {
+		row, column := benchPosition(room, benchIndex, params.RowWise)
+		for seat := 0; seat < seatsPerBench; seat++ {
+			seatColumn := (column-1)*seatsPerBench + seat
+			bin := ((row-1)%2)*2 + seatColumn%2
+			bins[bin] = append(bins[bin], seatSlot{benchIndex: benchIndex, seat: seat, row: row, column: column, seatColumn: seatColumn})
+		}
+	}
+	sort.SliceStable(bins, func(i, j int) bool { return len(bins[i]) > len(bins[j]) })
+	return bins
+}
+
+// paperFeasibility explains why the rooms cannot possibly seat every paper
+// without two adjacent students on it. No reasons does not guarantee the
+// greedy fill below seats everyone.
+func paperFeasibility(rooms []Room, params models.Params, papers []string, queues map[string][]string) []string {
+	totalSeats, maxPerPaper := 0, 0
+	for _, room := range rooms {
+		capacity := RoomCapacity(room, params)
+		totalSeats += capacity
+		maxPerPaper += min(len(paperBins(room, params)[0]), capacity)
+	}
+
+	reasons := []string{}
+	totalStudents := 0
+	for _, paper := range papers {
+		count := len(queues[paper])
+		totalStudents += count
+		if count > maxPerPaper {
+			reasons = append(reasons, fmt.Sprintf(
+				"paper %s has %d students but the selected rooms can seat at most %d of them without two adjacent",
+				paper, count, maxPerPaper))
+		}
+	}
+	if totalStudents > totalSeats {
+		reasons = append(reasons, fmt.Sprintf(
+			"%d students need seats but the selected rooms only have %d", totalStudents, totalSeats))
+	}
+	return reasons
+}
+
+// SolvePaperSeating seats students so that no two students writing the same
+// paper are horizontally, vertically or diagonally adjacent. studentPapers
+// maps each student ID to its paper. Students that cannot be seated are left
+// out of the plan and counted in an *InfeasibleError.
+func SolvePaperSeating(rooms []Room, students map[string][]string, studentPapers map[string]string, params models.Params, toe time.Time, doe time.Duration) (SeatingPlan, error) {
+	papers := []string{}
+	queues := map[string][]string{}
+	for _, branch := range params.Branches {
+		for _, studentID := range students[branch] {
+			paper := studentPapers[studentID]
+			if paper == "" {
+				paper = branch
+			}
+			if _, found := queues[paper]; !found {
+				papers = append(papers, paper)
+			}
+			queues[paper] = append(queues[paper], studentID)
+		}
+	}
+
+	plan := NewSeatingPlan(toe, doe)
+	reasons := paperFeasibility(rooms, params, papers, queues)
+
+	remaining := func() int {
+		total := 0
+		for _, paper := range papers {
+			total += len(queues[paper])
+		}
+		return total
+	}
+
+	for _, room := range rooms {
+		if remaining() == 0 {
+			break
+		}
+
+		capacity := RoomCapacity(room, params)
+		placed := []seatSlot{}
+		placedStudents := map[seatSlot]string{}
+		grid := map[[2]int]string{}
+
+		allowed := func(paper string, slot seatSlot) bool {
+			for dr := -1; dr <= 1; dr++ {
+				for dc := -1; dc <= 1; dc++ {
+					if (dr != 0 || dc != 0) && grid[[2]int{slot.row + dr, slot.seatColumn + dc}] == paper {
+						return false
+					}
+				}
+			}
+			return true
+		}
+
+		for _, slots := range paperBins(room, params) {
+			current := ""
+			for _, slot := range slots {
+				if len(placed) >= capacity {
+					break
+				}
+				// Keep filling with the same paper so roll numbers stay
+				// together, otherwise take the largest paper that has no
+				// neighbour on this seat.
+				if len(queues[current]) == 0 || !allowed(current, slot) {
+					current = ""
+					for _, paper := range papers {
+						if len(queues[paper]) == 0 || !allowed(paper, slot) {
+							continue
+						}
+						if current == "" || len(queues[paper]) > len(queues[current]) {
+							current = paper
+						}
+					}
+				}
+				if current == "" {
+					continue
+				}
+
+				grid[[2]int{slot.row, slot.seatColumn}] = current
+				placed = append(placed, slot)
+				placedStudents[slot] = queues[current][0]
+				queues[current] = queues[current][1:]
+			}
+		}
+
+		sort.Slice(placed, func(i, j int) bool {
+			if placed[i].benchIndex != placed[j].benchIndex {
+				return placed[i].benchIndex < placed[j].benchIndex
+			}
+			return placed[i].seat < placed[j].seat
+		})
+
+		assignedStudents := []SeatAssignment{}
+		for _, slot := range placed {
+			side := seatSide(room, params, slot.row, slot.column, slot.seat)
+			assignedStudents = append(assignedStudents, plan.seat(placedStudents[slot], slot.row, slot.column, side, grid[[2]int{slot.row, slot.seatColumn}]))
+		}
+
+		plan.Rooms = append(plan.Rooms, newRoomPlan(room, assignedStudents))
+	}
+
+	if remaining() > 0 {
+		infeasible := &InfeasibleError{Reasons: reasons, Unseated: map[string]int{}}
+		for _, paper := range papers {
+			if count := len(queues[paper]); count > 0 {
+				infeasible.Unseated[paper] = count
+				infeasible.Reasons = append(infeasible.Reasons, fmt.Sprintf(
+					"%d students writing %s could not be seated without an adjacent student on the same paper; add rooms or mix in more papers",
+					count, paper))
+			}
+		}
+		return plan, infeasible
+	}
+
+	return plan, nil
+}
@@ -1,20 +1,193 @@
 package main
 
 import (
+	"DevMaan707/UMS/config"
+	"DevMaan707/UMS/db"
 	"DevMaan707/UMS/handlers"
+	"DevMaan707/UMS/helpers"
+	"DevMaan707/UMS/middleware"
+	"DevMaan707/UMS/models"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
 )
 
 func main() {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Error loading configuration: %v", err)
+	}
+
+	database := db.ConnectMySQL(cfg.DatabaseDSN)
+	if err := db.Migrate(database); err != nil {
+		log.Fatalf("Error migrating database: %v", err)
+	}
+	userStore := db.NewUserStore(database)
+	revocationStore := db.NewRevocationStore(database)
+	go purgeRevocations(revocationStore)
+	if err := ensureAdmin(userStore, cfg); err != nil {
+		log.Fatalf("Error creating admin user: %v", err)
+	}
+
+	tokens, err := tokenConfig(cfg)
+	if err != nil {
+		log.Fatalf("Error loading signing keys: %v", err)
+	}
+	handlers.SetAssignmentStore(db.NewAssignmentStore(database))
+	handlers.SetRoomStore(db.NewRoomStore(database))
+	handlers.SetClassStore(db.NewClassStore(database))
+	handlers.SetExamStore(db.NewExamStore(database))
+	handlers.SetUserStore(userStore)
+	handlers.SetRevocationStore(revocationStore)
+	handlers.SetTokenConfig(tokens)
+	handlers.SetPDFCache(helpers.NewPDFCache(cfg.PDFCacheSize))
+	helpers.SetTimeZone(cfg.TimeZone)
+
+	if cfg.RollNumberFormat != "" {
+		parser, err := helpers.NewFormatRollNumberParser(cfg.RollNumberFormat)
+		if err != nil {
+			log.Fatalf("Error configuring roll numbers: %v", err)
+		}
+		helpers.SetRollNumberParser(parser)
+	} else if cfg.RollNumberPattern != "" {
+		parser, err := helpers.NewRegexRollNumberParser(cfg.RollNumberPattern)
+		if err != nil {
+			log.Fatalf("Error configuring roll numbers: %v", err)
+		}
+		helpers.SetRollNumberParser(parser)
+	}
+
 	router := gin.Default()
 
-	//router.Use(middleware.JWTAuthMiddleware())
+	router.POST("/auth/login", handlers.Login)
+	router.POST("/auth/refresh", handlers.RefreshToken)
+
+	authed := router.Group("/")
+	authed.Use(middleware.JWTAuthMiddleware(tokens, revocationStore))
+
+	authed.POST("/auth/logout", handlers.Logout)
+
+	// Students may only look up their own seat and hall ticket, and invigilators
+	// only seats in their own rooms; the handlers check.
+	authed.GET("/assignments/:student_id", handlers.GetStudentSpecificAssignment)
+	authed.GET("/assignments/:student_id/hall-ticket", handlers.GetHallTicket)
+	authed.POST("/hall-tickets/verify", middleware.RequirePermission(helpers.PermissionVerifyTicket), handlers.VerifyHallTicket)
+	authed.GET("/invigilator/rooms", middleware.RequirePermission(helpers.PermissionViewOwnRooms), handlers.GetInvigilatorRooms)
+
+	users := authed.Group("/", middleware.RequirePermission(helpers.PermissionManageUsers))
+	users.POST("/users", handlers.CreateUser)
+	users.POST("/users/:id/revoke-tokens", handlers.RevokeUserTokens)
+
+	plans := authed.Group("/", middleware.RequirePermission(helpers.PermissionManagePlans))
+	plans.POST("/test/generate-classes", handlers.AssignRoomsForExams)
+	plans.POST("/exams/plan/preview", handlers.PreviewExamPlan)
+	plans.DELETE("/plans", handlers.DeletePlans)
+	plans.DELETE("/plans/:id", handlers.DeletePlan)
+
+	duty := authed.Group("/", middleware.RequirePermission(helpers.PermissionAssignDuty))
+	duty.PUT("/plans/:id/invigilators", handlers.SetInvigilators)
+
+	views := authed.Group("/", middleware.RequirePermission(helpers.PermissionViewPlans))
+	views.GET("/assignments", handlers.GetAllAssignments)
+	views.GET("/assignments/conflicts", handlers.AuditConflicts)
+	views.GET("/generatepdfbytoe", handlers.GeneratePDFByTOE)
+	views.GET("/generatedoornotices", handlers.GenerateDoorNoticesPDF)
+	views.GET("/generateattendance", handlers.GenerateAttendancePDF)
 
-	router.POST("/test/generate-classes", handlers.AssignRoomsForExams)
-	router.GET("/assignments", handlers.GetAllAssignments)
-	router.GET("/assignments/:student_id", handlers.GetStudentSpecificAssignment)
-	router.GET("/generatepdfbytoe", handlers.GeneratePDFByTOE)
+	setup := authed.Group("/", middleware.RequirePermission(helpers.PermissionManageSetup))
+	setup.POST("/rooms", handlers.CreateRoom)
+	setup.POST("/rooms/import", handlers.ImportRooms)
+	setup.GET("/rooms", handlers.ListRooms)
+	setup.GET("/rooms/:id", handlers.GetRoom)
+	setup.PUT("/rooms/:id", handlers.UpdateRoom)
+	setup.DELETE("/rooms/:id", handlers.DeleteRoom)
+
+	setup.POST("/classes", handlers.CreateClass)
+	setup.POST("/classes/import", handlers.ImportRoster)
+	setup.GET("/classes", handlers.ListClasses)
+	setup.GET("/classes/:id", handlers.GetClass)
+	setup.POST("/classes/:id/students", handlers.AddStudents)
+	setup.PUT("/classes/:id/eligibility", handlers.UpdateEligibility)
+
+	setup.POST("/exams", handlers.CreateExam)
+	setup.GET("/exams", handlers.ListExams)
+	setup.GET("/exams/:id", handlers.GetExam)
+	setup.PUT("/exams/:id", handlers.UpdateExam)
+	setup.DELETE("/exams/:id", handlers.DeleteExam)
+	setup.POST("/exams/:id/sessions", handlers.AddExamSession)
+	setup.DELETE("/exams/:id/sessions/:session_id", handlers.DeleteExamSession)
 
 	router.Run()
 }
+
+func tokenConfig(cfg config.Config) (helpers.TokenConfig, error) {
+	tokens := helpers.TokenConfig{
+		Algorithm:     cfg.JWTAlgorithm,
+		AccessSecret:  []byte(cfg.JWTSecret),
+		RefreshSecret: []byte(cfg.JWTRefreshSecret),
+		KeyID:         cfg.JWTKeyID,
+		Issuer:        cfg.JWTIssuer,
+		Audience:      cfg.JWTAudience,
+		AccessTTL:     cfg.AccessTokenTTL,
+		RefreshTTL:    cfg.RefreshTokenTTL,
+	}
+	if cfg.JWTAlgorithm != helpers.AlgorithmRS256 {
+		return tokens, nil
+	}
+
+	pem, err := os.ReadFile(cfg.JWTPrivateKeyFile)
+	if err != nil {
+		return helpers.TokenConfig{}, err
+	}
+	if tokens.SigningKey, err = jwt.ParseRSAPrivateKeyFromPEM(pem); err != nil {
+		return helpers.TokenConfig{}, err
+	}
+	keys, err := helpers.LoadJWKSFile(cfg.JWTJWKSFile)
+	if err != nil {
+		return helpers.TokenConfig{}, err
+	}
+	publicKey, err := keys.PublicKey(cfg.JWTKeyID)
+	if err != nil {
+		return helpers.TokenConfig{}, err
+	}
+	if !publicKey.Equal(&tokens.SigningKey.PublicKey) {
+		return helpers.TokenConfig{}, fmt.Errorf("JWKS key %s does not match the private key", cfg.JWTKeyID)
+	}
+	tokens.Keys = keys
+	return tokens, nil
+}
+
+// ensureAdmin creates the configured admin account on first start so there
+// is someone to log in as.
+func ensureAdmin(store db.UserStore, cfg config.Config) error {
+	if cfg.AdminUsername == "" {
+		return nil
+	}
+	username := helpers.NormalizeUsername(cfg.AdminUsername)
+	_, err := store.GetUserByUsername(username)
+	if !errors.Is(err, db.ErrNotFound) {
+		return err
+	}
+
+	hash, err := helpers.HashPassword(cfg.AdminPassword)
+	if err != nil {
+		return err
+	}
+	return store.CreateUser(&models.User{Username: username, Name: "Administrator", Role: helpers.RoleAdmin, PasswordHash: hash})
+}
+
+// purgeRevocations drops revocations of tokens that have expired anyway, at
+// startup and then every hour.
+func purgeRevocations(store db.RevocationStore) {
+	for {
+		if err := store.PurgeExpired(time.Now()); err != nil {
+			log.Printf("Error purging revoked tokens: %v", err)
+		}
+		time.Sleep(time.Hour)
+	}
+}
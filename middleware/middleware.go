@@ -1,40 +1,84 @@
 package middleware
 
 import (
+	"DevMaan707/UMS/db"
+	"DevMaan707/UMS/helpers"
+	"errors"
 	"net/http"
 	"strings"
 
-	"github.com/dgrijalva/jwt-go"
 	"github.com/gin-gonic/gin"
 )
 
-var jwtSecret = []byte("your-secret-key")
+const ClaimsKey = "claims"
 
-// JWTAuthMiddleware ensures that the user is authenticated
-func JWTAuthMiddleware() gin.HandlerFunc {
+// Unauthorized ends the request with the 401 body used across the API.
+func Unauthorized(c *gin.Context, message string) {
+	c.Header("WWW-Authenticate", `Bearer realm="ums"`)
+	c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": message})
+}
+
+// JWTAuthMiddleware ensures that the user is authenticated with a token that
+// has not been revoked.
+func JWTAuthMiddleware(tokens helpers.TokenConfig, revocations db.RevocationStore) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Get the token from the Authorization header
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization token required"})
-			c.Abort()
+		scheme, tokenString, found := strings.Cut(c.GetHeader("Authorization"), " ")
+		tokenString = strings.TrimSpace(tokenString)
+		if !found || !strings.EqualFold(scheme, "Bearer") || tokenString == "" {
+			Unauthorized(c, "Authorization token required")
 			return
 		}
 
-		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
+		claims, err := helpers.ParseToken(tokens, tokenString, helpers.TokenTypeAccess)
+		if errors.Is(err, helpers.ErrTokenExpired) {
+			Unauthorized(c, "Token expired")
+			return
+		}
+		if err != nil {
+			Unauthorized(c, "Invalid token")
+			return
+		}
+		revoked, err := revocations.IsRevoked(claims.ID, claims.UserID, claims.IssuedAt.Time)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to check token"})
+			return
+		}
+		if revoked {
+			Unauthorized(c, "Token revoked")
+			return
+		}
 
-		// Parse the token
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			return jwtSecret, nil
-		})
+		// Set claims in context (for use in handlers)
+		c.Set("user_id", claims.UserID)
+		c.Set("username", claims.Username)
+		c.Set(ClaimsKey, claims)
+		c.Next()
+	}
+}
 
-		if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-			// Set claims in context (for use in handlers)
-			c.Set("user_id", claims["user_id"])
-			c.Next()
-		} else {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token", "message": err.Error()})
-			c.Abort()
+// RequirePermission lets the request through only if the token carries
+// permission. It must run after JWTAuthMiddleware.
+func RequirePermission(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, found := CurrentClaims(c)
+		if !found {
+			Unauthorized(c, "Authorization token required")
+			return
 		}
+		if !claims.Can(permission) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+			return
+		}
+		c.Next()
+	}
+}
+
+func CurrentClaims(c *gin.Context) (*helpers.Claims, bool) {
+	value, found := c.Get(ClaimsKey)
+	if !found {
+		return nil, false
 	}
+	claims, ok := value.(*helpers.Claims)
+	return claims, ok
 }
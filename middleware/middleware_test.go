@@ -0,0 +1,281 @@
+package middleware
+
+import (
+	"DevMaan707/UMS/helpers"
+	"DevMaan707/UMS/models"
+	"crypto/rand"
+	"crypto/rsa"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testKeyID        = "test-key"
+	testAccessSecret = "0123456789abcdef0123456789abcdef"
+	wwwAuthenticate  = `Bearer realm="ums"`
+)
+
+type testKeySet map[string]*rsa.PublicKey
+
+func (k testKeySet) PublicKey(kid string) (*rsa.PublicKey, error) {
+	key, found := k[kid]
+	if !found {
+		return nil, fmt.Errorf("unknown key %q", kid)
+	}
+	return key, nil
+}
+
+// testRevocations revokes the token IDs it holds.
+type testRevocations map[string]bool
+
+func (r testRevocations) RevokeToken(tokenID string, userID uint, expiresAt time.Time, reason string) error {
+	r[tokenID] = true
+	return nil
+}
+
+func (r testRevocations) RevokeUserTokens(userID uint, expiresAt time.Time, reason string) error {
+	return nil
+}
+
+func (r testRevocations) IsRevoked(tokenID string, userID uint, issuedAt time.Time) (bool, error) {
+	return r[tokenID], nil
+}
+
+func (r testRevocations) PurgeExpired(now time.Time) error {
+	return nil
+}
+
+func testTokenConfig(t *testing.T) helpers.TokenConfig {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generating key: %v", err)
+	}
+	return helpers.TokenConfig{
+		Algorithm:     helpers.AlgorithmRS256,
+		AccessSecret:  []byte(testAccessSecret),
+		RefreshSecret: []byte("fedcba9876543210fedcba9876543210"),
+		SigningKey:    key,
+		KeyID:         testKeyID,
+		Keys:          testKeySet{testKeyID: &key.PublicKey},
+		Issuer:        "ums",
+		Audience:      "ums-api",
+		AccessTTL:     time.Minute,
+		RefreshTTL:    time.Hour,
+	}
+}
+
+// accessClaims are the claims of a valid access token.
+func accessClaims(now time.Time) *helpers.Claims {
+	return &helpers.Claims{
+		UserID:    1,
+		Username:  "admin",
+		Role:      helpers.RoleAdmin,
+		TokenType: helpers.TokenTypeAccess,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        "token-1",
+			Issuer:    "ums",
+			Audience:  jwt.ClaimStrings{"ums-api"},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Minute)),
+		},
+	}
+}
+
+func signRS256(t *testing.T, config helpers.TokenConfig, kid string, claims jwt.Claims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(config.SigningKey)
+	if err != nil {
+		t.Fatalf("signing token: %v", err)
+	}
+	return signed
+}
+
+func TestJWTAuthMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	config := testTokenConfig(t)
+	now := time.Now()
+
+	withClaims := func(change func(claims *helpers.Claims)) func(t *testing.T) string {
+		return func(t *testing.T) string {
+			claims := accessClaims(now)
+			change(claims)
+			return "Bearer " + signRS256(t, config, testKeyID, claims)
+		}
+	}
+	header := func(value string) func(t *testing.T) string {
+		return func(t *testing.T) string { return value }
+	}
+
+	tests := []struct {
+		name          string
+		authorization func(t *testing.T) string
+		status        int
+		body          string
+	}{
+		{
+			name:          "missing header",
+			authorization: header(""),
+			status:        http.StatusUnauthorized,
+			body:          `{"error":"Authorization token required"}`,
+		},
+		{
+			name:          "wrong scheme",
+			authorization: header("Basic YWRtaW46cGFzc3dvcmQ="),
+			status:        http.StatusUnauthorized,
+			body:          `{"error":"Authorization token required"}`,
+		},
+		{
+			name:          "bearer without token",
+			authorization: header("Bearer "),
+			status:        http.StatusUnauthorized,
+			body:          `{"error":"Authorization token required"}`,
+		},
+		{
+			name:          "malformed token",
+			authorization: header("Bearer not.a.token"),
+			status:        http.StatusUnauthorized,
+			body:          `{"error":"Invalid token"}`,
+		},
+		{
+			name: "HS256 token when RS256 is configured",
+			authorization: func(t *testing.T) string {
+				signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, accessClaims(now)).SignedString([]byte(testAccessSecret))
+				if err != nil {
+					t.Fatalf("signing token: %v", err)
+				}
+				return "Bearer " + signed
+			},
+			status: http.StatusUnauthorized,
+			body:   `{"error":"Invalid token"}`,
+		},
+		{
+			name: "alg none",
+			authorization: func(t *testing.T) string {
+				signed, err := jwt.NewWithClaims(jwt.SigningMethodNone, accessClaims(now)).SignedString(jwt.UnsafeAllowNoneSignatureType)
+				if err != nil {
+					t.Fatalf("signing token: %v", err)
+				}
+				return "Bearer " + signed
+			},
+			status: http.StatusUnauthorized,
+			body:   `{"error":"Invalid token"}`,
+		},
+		{
+			name: "expired",
+			authorization: withClaims(func(claims *helpers.Claims) {
+				claims.IssuedAt = jwt.NewNumericDate(now.Add(-time.Hour))
+				claims.ExpiresAt = jwt.NewNumericDate(now.Add(-time.Minute))
+			}),
+			status: http.StatusUnauthorized,
+			body:   `{"error":"Token expired"}`,
+		},
+		{
+			name:          "missing exp",
+			authorization: withClaims(func(claims *helpers.Claims) { claims.ExpiresAt = nil }),
+			status:        http.StatusUnauthorized,
+			body:          `{"error":"Invalid token"}`,
+		},
+		{
+			name:          "nbf in the future",
+			authorization: withClaims(func(claims *helpers.Claims) { claims.NotBefore = jwt.NewNumericDate(now.Add(time.Hour)) }),
+			status:        http.StatusUnauthorized,
+			body:          `{"error":"Invalid token"}`,
+		},
+		{
+			name:          "wrong issuer",
+			authorization: withClaims(func(claims *helpers.Claims) { claims.Issuer = "someone-else" }),
+			status:        http.StatusUnauthorized,
+			body:          `{"error":"Invalid token"}`,
+		},
+		{
+			name:          "wrong audience",
+			authorization: withClaims(func(claims *helpers.Claims) { claims.Audience = jwt.ClaimStrings{"other-api"} }),
+			status:        http.StatusUnauthorized,
+			body:          `{"error":"Invalid token"}`,
+		},
+		{
+			name: "refresh token",
+			authorization: func(t *testing.T) string {
+				pair, err := helpers.IssueTokens(config, models.User{ID: 1, Username: "admin", Role: helpers.RoleAdmin})
+				if err != nil {
+					t.Fatalf("issuing tokens: %v", err)
+				}
+				return "Bearer " + pair.RefreshToken
+			},
+			status: http.StatusUnauthorized,
+			body:   `{"error":"Invalid token"}`,
+		},
+		{
+			name:          "refresh type signed with the access key",
+			authorization: withClaims(func(claims *helpers.Claims) { claims.TokenType = helpers.TokenTypeRefresh }),
+			status:        http.StatusUnauthorized,
+			body:          `{"error":"Invalid token"}`,
+		},
+		{
+			name: "unknown kid",
+			authorization: func(t *testing.T) string {
+				return "Bearer " + signRS256(t, config, "retired-key", accessClaims(now))
+			},
+			status: http.StatusUnauthorized,
+			body:   `{"error":"Invalid token"}`,
+		},
+		{
+			name:          "revoked",
+			authorization: withClaims(func(claims *helpers.Claims) { claims.ID = "revoked-token" }),
+			status:        http.StatusUnauthorized,
+			body:          `{"error":"Token revoked"}`,
+		},
+		{
+			name: "valid",
+			authorization: func(t *testing.T) string {
+				pair, err := helpers.IssueTokens(config, models.User{ID: 1, Username: "admin", Role: helpers.RoleAdmin})
+				if err != nil {
+					t.Fatalf("issuing tokens: %v", err)
+				}
+				return "Bearer " + pair.AccessToken
+			},
+			status: http.StatusOK,
+			body:   `{"user_id":1}`,
+		},
+	}
+
+	router := gin.New()
+	router.Use(JWTAuthMiddleware(config, testRevocations{"revoked-token": true}))
+	router.GET("/", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"user_id": c.GetUint("user_id")})
+	})
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/", nil)
+			if authorization := test.authorization(t); authorization != "" {
+				request.Header.Set("Authorization", authorization)
+			}
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, request)
+
+			if recorder.Code != test.status {
+				t.Errorf("status = %d, want %d", recorder.Code, test.status)
+			}
+			if body := recorder.Body.String(); body != test.body {
+				t.Errorf("body = %s, want %s", body, test.body)
+			}
+			wantChallenge := ""
+			if test.status == http.StatusUnauthorized {
+				wantChallenge = wwwAuthenticate
+			}
+			if challenge := recorder.Header().Get("WWW-Authenticate"); challenge != wantChallenge {
+				t.Errorf("WWW-Authenticate = %q, want %q", challenge, wantChallenge)
+			}
+		})
+	}
+}
@@ -13,10 +13,26 @@ type Class struct {
 	UpdatedAt    time.Time
 	DeletedAt    gorm.DeletedAt `gorm:"index"`
 	ClassName    string         `json:"class_name"`
-	StudentIDs   []string       `json:"student_ids"`
-	Year         int            `json:"year"`
-	DetainedList string         `json:"detained_list"`
-	Branch       string         `json:"branch"`
+	StudentIDs   []string       `json:"student_ids" gorm:"serializer:json;type:text"`
+	Year         int            `json:"year" gorm:"index:idx_class_roster"`
+	DetainedList []string       `json:"detained_list" gorm:"serializer:json;type:text"`
+	DebarredList []string       `json:"debarred_list" gorm:"serializer:json;type:text"`
+	Branch       string         `json:"branch" gorm:"size:32;index:idx_class_roster"`
+	Section      string         `json:"section" gorm:"size:8;index:idx_class_roster"`
+	Students     []Student      `json:"students,omitempty"`
+}
+
+type Student struct {
+	ID        uint `gorm:"primaryKey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+	StudentID string         `json:"student_id" gorm:"size:32;uniqueIndex"`
+	Name      string         `json:"name"`
+	ClassID   uint           `json:"class_id" gorm:"index"`
+	Branch    string         `json:"branch" gorm:"size:32"`
+	Year      int            `json:"year"`
+	Section   string         `json:"section" gorm:"size:8"`
 }
 
 type Item struct {
@@ -37,9 +53,17 @@ type Room struct {
 	CreatedAt     time.Time
 	UpdatedAt     time.Time
 	DeletedAt     gorm.DeletedAt `gorm:"index"`
-	RoomType      string         `json:"room_type"`
+	Block         string         `json:"block" gorm:"size:32;index;uniqueIndex:idx_room_block_number"`
+	Floor         int            `json:"floor"`
+	Building      string         `json:"building" gorm:"size:64"`
+	Door          string         `json:"door" gorm:"size:16"`
+	RoomType      string         `json:"room_type" gorm:"size:32;index"`
+	Rows          int            `json:"rows"`
+	Columns       int            `json:"columns"`
+	SeatsPerBench int            `json:"seats_per_bench"`
+	Spacing       float64        `json:"spacing"`
 	Capacity      int            `json:"capacity"`
-	RoomNumber    string         `json:"room_number"`
+	RoomNumber    string         `json:"room_number" gorm:"size:32;index;uniqueIndex:idx_room_block_number"`
 	RoomTimetable string         `json:"room_timetable"`
 	ClassAssigned string         `json:"class_assigned"`
 }
@@ -56,14 +80,134 @@ type Assigned struct {
 }
 
 type ExamAssignment struct {
-	ID         uint `gorm:"primaryKey"`
-	CreatedAt  time.Time
-	UpdatedAt  time.Time
-	DeletedAt  gorm.DeletedAt `gorm:"index"`
-	ExamID     int            `json:"exam_id"`
-	RoomID     int            `json:"room_id"`
-	RoomNumber string         `json:"room_number"`
-	StudentIDs []int          `json:"student_ids"`
+	ID            uint `gorm:"primaryKey"`
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	DeletedAt     gorm.DeletedAt   `gorm:"index"`
+	ExamID        uint             `json:"exam_id" gorm:"index"`
+	ExamSessionID uint             `json:"exam_session_id" gorm:"index"`
+	RoomID        int              `json:"room_id"`
+	RoomNumber    string           `json:"room_number" gorm:"index"`
+	Block         string           `json:"block"`
+	Floor         int              `json:"floor"`
+	Building      string           `json:"building"`
+	TOE           time.Time        `json:"toe" gorm:"index"`
+	DOE           string           `json:"doe"`
+	EndTime       time.Time        `json:"end_time" gorm:"index"`
+	Stats         RoomStats        `json:"stats" gorm:"serializer:json;type:text"`
+	Seats         []SeatAssignment `json:"seats"`
+}
+
+// RoomStats summarises who sits in one room of a seating plan.
+type RoomStats struct {
+	Headcount        int               `json:"headcount"`
+	Capacity         int               `json:"capacity"`
+	SeatsUsed        int               `json:"seats_used"`
+	SeatsFree        int               `json:"seats_free"`
+	Branches         map[string]int    `json:"branches"`
+	Years            map[int]int       `json:"years"`
+	Sections         map[string]int    `json:"sections"`
+	RollNumberRanges []RollNumberRange `json:"roll_number_ranges"`
+}
+
+type RollNumberRange struct {
+	Prefix string `json:"prefix"`
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Count  int    `json:"count"`
+}
+
+type SeatAssignment struct {
+	ID               uint `gorm:"primaryKey"`
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	DeletedAt        gorm.DeletedAt `gorm:"index"`
+	ExamAssignmentID uint           `json:"exam_assignment_id" gorm:"index"`
+	ExamSessionID    uint           `json:"exam_session_id" gorm:"index:idx_seat_student_session"`
+	StudentID        string         `json:"student_id" gorm:"size:32;index:idx_seat_student_toe;index:idx_seat_student_session"`
+	RoomNumber       string         `json:"room_number"`
+	Row              int            `json:"row"`
+	Column           int            `json:"column"`
+	Side             string         `json:"side"`
+	Subject          string         `json:"subject"`
+	TOE              time.Time      `json:"toe" gorm:"index:idx_seat_student_toe"`
+	DOE              string         `json:"doe"`
+}
+
+type Exam struct {
+	ID        uint `gorm:"primaryKey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+	ExamName  string         `json:"exam_name"`
+	ExamType  string         `json:"exam_type" gorm:"size:32;index"`
+	Sessions  []ExamSession  `json:"sessions"`
+}
+
+type ExamSession struct {
+	ID        uint `gorm:"primaryKey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+	ExamID    uint           `json:"exam_id" gorm:"index"`
+	StartTime time.Time      `json:"start_time" gorm:"index"`
+	Duration  string         `json:"duration"`
+	Papers    []ExamPaper    `json:"papers"`
+}
+
+type ExamPaper struct {
+	ID            uint `gorm:"primaryKey"`
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	DeletedAt     gorm.DeletedAt `gorm:"index"`
+	ExamSessionID uint           `json:"exam_session_id" gorm:"index"`
+	Branch        string         `json:"branch" gorm:"size:32"`
+	Year          int            `json:"year"`
+	SubjectCode   string         `json:"subject_code" gorm:"size:32"`
+	SubjectName   string         `json:"subject_name"`
+}
+
+type User struct {
+	ID           uint `gorm:"primaryKey"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	DeletedAt    gorm.DeletedAt `gorm:"index"`
+	Username     string         `json:"username" gorm:"size:64;uniqueIndex"`
+	Name         string         `json:"name"`
+	Role         string         `json:"role" gorm:"size:32;index"`
+	StudentID    string         `json:"student_id,omitempty" gorm:"size:32;index"`
+	PasswordHash string         `json:"-"`
+}
+
+// TokenRevocation blocks one token by its ID. TokenID is the primary key,
+// so a token can only be revoked once; refresh tokens rely on that to be
+// single use. Rows can be purged once ExpiresAt has passed since the token
+// has expired by then.
+type TokenRevocation struct {
+	TokenID   string `json:"token_id" gorm:"primaryKey;size:64"`
+	CreatedAt time.Time
+	UserID    uint      `json:"user_id" gorm:"index"`
+	ExpiresAt time.Time `json:"expires_at" gorm:"index"`
+	Reason    string    `json:"reason" gorm:"size:64"`
+}
+
+// UserRevocation blocks every token issued to UserID up to RevokedAt. Rows
+// can be purged once ExpiresAt has passed.
+type UserRevocation struct {
+	ID        uint `gorm:"primaryKey"`
+	CreatedAt time.Time
+	UserID    uint      `json:"user_id" gorm:"index"`
+	RevokedAt time.Time `json:"revoked_at"`
+	ExpiresAt time.Time `json:"expires_at" gorm:"index"`
+	Reason    string    `json:"reason" gorm:"size:64"`
+}
+
+// Invigilation puts an invigilator on duty in one room of a seating plan.
+type Invigilation struct {
+	ID               uint `gorm:"primaryKey"`
+	CreatedAt        time.Time
+	ExamAssignmentID uint `json:"exam_assignment_id" gorm:"uniqueIndex:idx_invigilation"`
+	UserID           uint `json:"user_id" gorm:"uniqueIndex:idx_invigilation;index"`
 }
 
 type AddValuesRequest struct {
@@ -75,16 +219,26 @@ type GenerateClassesRequest struct {
 	Params Params `json:"params"`
 }
 type Params struct {
-	Blocks                 []string `json:"blocks"`
-	Branches               []string `json:"branches"`
-	Years                  []int    `json:"years"`
-	SingleChild            bool     `json:"single_child"`
-	NumberOfBranchesInRoom int      `json:"number_of_branches"`
-	RoomTypes              []string `json:"room_types"`
-	InternalShuffle        bool     `json:"internal_shuffle"`
-	RowWise                bool     `json:"row_wise"`
-	TOE                    string   `json:"toe"`
-	DOE                    string   `json:"doe"`
+	Blocks                 []string          `json:"blocks"`
+	Branches               []string          `json:"branches"`
+	Years                  []int             `json:"years"`
+	Sections               []string          `json:"sections"`
+	IncludeStudents        []string          `json:"include_students"`
+	SingleChild            bool              `json:"single_child"`
+	SingleChildPosition    string            `json:"single_child_position"`
+	NumberOfBranchesInRoom int               `json:"number_of_branches"`
+	Pattern                string            `json:"pattern"`
+	Papers                 map[string]string `json:"papers"`
+	SeparatePapers         bool              `json:"separate_papers"`
+	SessionID              uint              `json:"session_id"`
+	RoomTypes              []string          `json:"room_types"`
+	InternalShuffle        bool              `json:"internal_shuffle"`
+	RowWise                bool              `json:"row_wise"`
+	DryRun                 bool              `json:"dry_run"`
+	Force                  bool              `json:"force"`
+	Replace                bool              `json:"replace"`
+	TOE                    string            `json:"toe"`
+	DOE                    string            `json:"doe"`
 }
 
 type Details struct {